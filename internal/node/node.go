@@ -2,6 +2,7 @@ package node
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/downflux/go-geometry/epsilon"
 	"github.com/downflux/go-geometry/nd/hyperrectangle"
@@ -50,7 +51,7 @@ var (
 
 func FSM(path []Child, e Edge) []Child {
 	if e != EdgeN && e != EdgeE && e != EdgeS && e != EdgeW {
-		panic(fmt.Sprintf("invalid edge value %v", e))
+		panic(fmt.Sprintf("invalid edge value %d", uint(e)))
 	}
 
 	buf := make([]Child, len(path))
@@ -113,6 +114,31 @@ const (
 	EdgeNone
 )
 
+func (e Edge) String() string {
+	switch e {
+	case EdgeN:
+		return "N"
+	case EdgeNE:
+		return "NE"
+	case EdgeE:
+		return "E"
+	case EdgeSE:
+		return "SE"
+	case EdgeS:
+		return "S"
+	case EdgeSW:
+		return "SW"
+	case EdgeW:
+		return "W"
+	case EdgeNW:
+		return "NW"
+	case EdgeNone:
+		return "None"
+	default:
+		panic(fmt.Sprintf("invalid edge value %d", uint(e)))
+	}
+}
+
 func (e Edge) Invert() Edge {
 	switch e {
 	case EdgeN:
@@ -132,14 +158,64 @@ func (e Edge) Invert() Edge {
 	case EdgeNW:
 		return EdgeSE
 	default:
-		panic(fmt.Sprintf("invalid edge value %v", e))
+		panic(fmt.Sprintf("invalid edge value %d", uint(e)))
 	}
 }
 
+// Event describes a structural change to a node.
+type Event uint
+
+const (
+	// EventSplit fires after a leaf node has been split into four children.
+	EventSplit Event = iota
+
+	// EventMerge fires after the children of a node have been collapsed,
+	// i.e. the node has become a leaf again.
+	EventMerge
+
+	// EventOccupancy fires after an object has been added to or removed
+	// from a leaf node.
+	EventOccupancy
+)
+
+func (e Event) String() string {
+	switch e {
+	case EventSplit:
+		return "Split"
+	case EventMerge:
+		return "Merge"
+	case EventOccupancy:
+		return "Occupancy"
+	default:
+		panic(fmt.Sprintf("invalid event value %d", uint(e)))
+	}
+}
+
+// Hook is called whenever a node in the tree is structurally changed.
+type Hook func(e Event, n *N)
+
+// Policy checks if the leaf n should be split before adding an object with
+// the input AABB. Leaves at the depth floor are never split.
+type Policy func(n *N, aabb hyperrectangle.R) bool
+
 type N struct {
 	tolerance float64
 	floor     int
 
+	// policy overrides the tolerance check when deciding whether or not to
+	// split a leaf, if set.
+	policy Policy
+
+	// lazy disables collapsing empty siblings on Remove. Empty siblings
+	// are instead merged by an explicit call to Collapse or Compact.
+	lazy bool
+
+	// hook is shared by all nodes in the tree.
+	hook Hook
+
+	// scratch is shared by all nodes in the tree.
+	scratch *scratch
+
 	depth int
 
 	parent *N
@@ -155,6 +231,20 @@ type N struct {
 	lookup map[id.ID]bool
 }
 
+// scratch holds buffers which are reused across calls to Insert and Remove,
+// which avoids allocating when objects repeatedly move within an already
+// split region of the tree.
+type scratch struct {
+	open       []*N
+	candidates *pq.PQ[*N]
+}
+
+func newScratch() *scratch {
+	return &scratch{
+		candidates: pq.New[*N](0, pq.PMax),
+	}
+}
+
 // New returns a root
 func New(aabb hyperrectangle.R, tolerance float64, floor int) *N {
 	if floor <= 0 {
@@ -165,6 +255,50 @@ func New(aabb hyperrectangle.R, tolerance float64, floor int) *N {
 		aabb:      aabb,
 		tolerance: tolerance,
 		floor:     floor,
+		scratch:   newScratch(),
+		lookup:    map[id.ID]bool{},
+	}
+}
+
+// NewWithPolicy returns a root whose leaves are split according to the input
+// policy.
+func NewWithPolicy(aabb hyperrectangle.R, p Policy, floor int) *N {
+	n := New(aabb, 0, floor)
+	n.policy = p
+	return n
+}
+
+// Observe sets the hook function of all nodes under n. A nil hook disables
+// event reporting.
+func (n *N) Observe(f Hook) {
+	open := []*N{n}
+	var m *N
+	for len(open) > 0 {
+		m, open = open[0], open[1:]
+		m.hook = f
+		if !m.IsLeaf() {
+			open = append(open, m.children[:]...)
+		}
+	}
+}
+
+// SetLazy sets whether or not Remove collapses empty siblings for all nodes
+// under n.
+func (n *N) SetLazy(lazy bool) {
+	open := []*N{n}
+	var m *N
+	for len(open) > 0 {
+		m, open = open[0], open[1:]
+		m.lazy = lazy
+		if !m.IsLeaf() {
+			open = append(open, m.children[:]...)
+		}
+	}
+}
+
+func (n *N) notify(e Event) {
+	if n.hook != nil {
+		n.hook(e, n)
 	}
 }
 
@@ -179,6 +313,19 @@ func Get(n *N, path []Child) *N {
 	return n
 }
 
+// Find returns the node under n with the input ID, or nil if no such node
+// exists. The ID is relative to n, i.e. Find(n, "") returns n itself.
+func Find(n *N, x string) *N {
+	for _, r := range x {
+		c := Child(r - '0')
+		if c >= ChildNone || n.IsLeaf() {
+			return nil
+		}
+		n = n.children[c]
+	}
+	return n
+}
+
 func Path(n *N) []Child {
 	path := make([]Child, n.depth)
 	for m := n; m.parent != nil; m = m.parent {
@@ -195,9 +342,87 @@ func ID(path []Child) string {
 	return buf
 }
 
-func (n *N) Path() []Child { return n.cachePath }
-func (n *N) ID() string    { return n.cacheID }
-func (n *N) IsLeaf() bool  { return n.children[ChildNE] == nil }
+// morton maps each quadrant to its rank in Morton (Z) order, i.e. SW, SE, NW,
+// NE.
+var morton = [...]int{
+	ChildNE: 3,
+	ChildSE: 1,
+	ChildSW: 0,
+	ChildNW: 2,
+}
+
+// Less checks if the node with ID x precedes the node with ID y in Morton
+// order. Ancestors precede their descendants. Less is a total order over all
+// strings; characters which do not denote a quadrant sort after those which
+// do.
+func Less(x string, y string) bool {
+	rank := func(b byte) int {
+		if c := Child(b - '0'); c < ChildNone {
+			return morton[c]
+		}
+		return int(ChildNone) + int(b)
+	}
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if a, b := rank(x[i]), rank(y[i]); a != b {
+			return a < b
+		}
+	}
+	return len(x) < len(y)
+}
+
+func (n *N) Path() []Child          { return n.cachePath }
+func (n *N) ID() string             { return n.cacheID }
+func (n *N) IsLeaf() bool           { return n.children[ChildNE] == nil }
+func (n *N) AABB() hyperrectangle.R { return n.aabb }
+func (n *N) Depth() int             { return n.depth }
+func (n *N) Parent() *N             { return n.parent }
+func (n *N) Corner() Child          { return n.corner }
+func (n *N) Floor() int             { return n.floor }
+
+// Children returns the child nodes of n, indexed by the Child quadrant. Leaf
+// nodes return nil.
+func (n *N) Children() []*N {
+	if n.IsLeaf() {
+		return nil
+	}
+	return n.children[:]
+}
+
+// Lookup returns the set of objects which overlap with the node. The returned
+// map must not be modified by the caller.
+func (n *N) Lookup() map[id.ID]bool { return n.lookup }
+
+// IDs returns the objects which overlap with the node. Only leaf nodes store
+// objects.
+func (n *N) IDs() []id.ID {
+	ids := make([]id.ID, 0, len(n.lookup))
+	for x := range n.lookup {
+		ids = append(ids, x)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Leaf returns the leaf node under n which contains the input point. If the
+// point lies on the boundary between multiple leaves, the first matching child
+// in ChildNE, ChildSE, ChildSW, ChildNW order is chosen. Leaf returns nil if
+// the point lies outside n.
+func (n *N) Leaf(p vector.V) *N {
+	if !n.aabb.In(p) {
+		return nil
+	}
+
+	m := n
+	for !m.IsLeaf() {
+		for _, c := range m.children {
+			if c.aabb.In(p) {
+				m = c
+				break
+			}
+		}
+	}
+	return m
+}
 
 func (n *N) Edge(e Edge) []*N {
 	children := make([]*N, 0, 16)
@@ -229,13 +454,34 @@ func (n *N) Edge(e Edge) []*N {
 		case EdgeNW:
 			open = append(open, m.children[ChildNW])
 		default:
-			panic(fmt.Sprintf("invalid edge %v", e))
+			panic(fmt.Sprintf("invalid edge %d", uint(e)))
 		}
 	}
 
 	return children
 }
 
+// Leaves returns all leaf nodes under n which overlap with the input
+// rectangle.
+func (n *N) Leaves(q hyperrectangle.R) []*N {
+	leaves := make([]*N, 0, 16)
+
+	open := []*N{n}
+	var m *N
+	for len(open) > 0 {
+		m, open = open[0], open[1:]
+		if hyperrectangle.Disjoint(q, m.aabb) {
+			continue
+		}
+		if m.IsLeaf() {
+			leaves = append(leaves, m)
+			continue
+		}
+		open = append(open, m.children[:]...)
+	}
+	return leaves
+}
+
 func (n *N) split(data map[id.ID]hyperrectangle.R) {
 	if n.depth == n.floor {
 		panic("cannot split past the depth limit")
@@ -272,7 +518,11 @@ func (n *N) split(data map[id.ID]hyperrectangle.R) {
 		c.parent = n
 		c.lookup = make(map[id.ID]bool, len(n.lookup))
 		c.tolerance = n.tolerance
+		c.policy = n.policy
+		c.lazy = n.lazy
 		c.floor = n.floor
+		c.hook = n.hook
+		c.scratch = n.scratch
 		c.cachePath = Path(c)
 		c.cacheID = ID(c.cachePath)
 
@@ -285,15 +535,19 @@ func (n *N) split(data map[id.ID]hyperrectangle.R) {
 	}
 
 	n.lookup = map[id.ID]bool{}
+
+	n.notify(EventSplit)
 }
 
 func (n *N) Insert(x id.ID, data map[id.ID]hyperrectangle.R) {
 	aabb := data[x]
+	if n.scratch == nil {
+		n.scratch = newScratch()
+	}
 
-	open := []*N{n}
-	var m *N
-	for len(open) > 0 {
-		m, open = open[0], open[1:]
+	open := append(n.scratch.open, n)
+	for i := 0; i < len(open); i++ {
+		m := open[i]
 
 		if hyperrectangle.Disjoint(aabb, m.aabb) {
 			continue
@@ -310,11 +564,18 @@ func (n *N) Insert(x id.ID, data map[id.ID]hyperrectangle.R) {
 			continue
 		}
 
-		if m.depth >= m.floor || epsilon.Absolute(m.tolerance).Within(
-			hyperrectangle.V(m.aabb),
-			hyperrectangle.V(aabb),
-		) {
+		// Leaves which are fully covered by all of their objects are
+		// never split, as the children would immediately be merged back
+		// by collapse.
+		if m.depth >= m.floor || m.covered(data) && hyperrectangle.Contains(aabb, m.aabb) || !m.divide(aabb) {
+			if m.lookup == nil {
+				m.lookup = map[id.ID]bool{}
+			}
 			m.lookup[x] = true
+			m.notify(EventOccupancy)
+			if !n.lazy {
+				n.scratch.candidates.Push(m, float64(m.depth))
+			}
 		} else {
 			m.split(data)
 			open = append(
@@ -326,17 +587,45 @@ func (n *N) Insert(x id.ID, data map[id.ID]hyperrectangle.R) {
 			)
 		}
 	}
+	n.scratch.release(open)
+
+	if !n.lazy {
+		collapse(n.scratch.candidates, data)
+	}
+}
+
+// release clears the input buffer, which was obtained from the scratch space,
+// and returns it to the scratch space for reuse. Node references are cleared
+// to avoid retaining nodes discarded by later merges.
+func (s *scratch) release(open []*N) {
+	for i := range open {
+		open[i] = nil
+	}
+	s.open = open[:0]
+}
+
+// divide checks if the leaf n should be split before adding an object with the
+// input AABB. By default, leaves are split unless the leaf volume is within
+// tolerance of the object volume.
+func (n *N) divide(aabb hyperrectangle.R) bool {
+	if n.policy != nil {
+		return n.policy(n, aabb)
+	}
+	return !epsilon.Absolute(n.tolerance).Within(
+		hyperrectangle.V(n.aabb),
+		hyperrectangle.V(aabb),
+	)
 }
 
 func (n *N) Remove(x id.ID, data map[id.ID]hyperrectangle.R) {
 	aabb := data[x]
+	if n.scratch == nil {
+		n.scratch = newScratch()
+	}
 
-	candidates := pq.New[*N](0, pq.PMax)
-
-	open := []*N{n}
-	var m *N
-	for len(open) > 0 {
-		m, open = open[0], open[1:]
+	open := append(n.scratch.open, n)
+	for i := 0; i < len(open); i++ {
+		m := open[i]
 
 		if hyperrectangle.Disjoint(aabb, m.aabb) {
 			continue
@@ -355,33 +644,245 @@ func (n *N) Remove(x id.ID, data map[id.ID]hyperrectangle.R) {
 
 		if m.lookup[x] {
 			delete(m.lookup, x)
+			m.notify(EventOccupancy)
 		}
 
-		if len(m.lookup) == 0 {
-			candidates.Push(m, float64(m.depth))
+		if !n.lazy {
+			n.scratch.candidates.Push(m, float64(m.depth))
 		}
 	}
+	n.scratch.release(open)
 
-	// Collapse parents.
+	if !n.lazy {
+		collapse(n.scratch.candidates, data)
+	}
+}
+
+// collapse merges the parents of the input candidate nodes, deepest first,
+// and continues upwards for as long as merges occur.
+func collapse(candidates *pq.PQ[*N], data map[id.ID]hyperrectangle.R) {
 	for !candidates.Empty() {
-		m, _ = candidates.Pop()
-		if p := m.parent; p != nil {
-			empty := true
-			for _, c := range p.children {
-				if len(c.lookup) != 0 {
-					empty = false
-				}
+		m, _ := candidates.Pop()
+		if p := m.parent; p != nil && p.Collapse(data) {
+			candidates.Push(p, float64(p.depth))
+		}
+	}
+}
+
+// covered checks if every object in the leaf n fully covers n.
+func (n *N) covered(data map[id.ID]hyperrectangle.R) bool {
+	for x := range n.lookup {
+		if !hyperrectangle.Contains(data[x], n.aabb) {
+			return false
+		}
+	}
+	return true
+}
+
+// Collapsible checks if n is an internal node whose children are all leaves
+// holding the same set of objects, each of which fully covers n. Notably, this
+// includes the case where all children are empty.
+func (n *N) Collapsible(data map[id.ID]hyperrectangle.R) bool {
+	if n.IsLeaf() {
+		return false
+	}
+	set := n.children[ChildNE].lookup
+	for _, c := range n.children {
+		if !c.IsLeaf() || len(c.lookup) != len(set) {
+			return false
+		}
+	}
+	for x := range set {
+		if !hyperrectangle.Contains(data[x], n.aabb) {
+			return false
+		}
+		for _, c := range n.children {
+			if !c.lookup[x] {
+				return false
 			}
+		}
+	}
+	return true
+}
 
-			if empty {
-				for x, c := range p.children {
-					c.parent = nil
-					p.children[x] = nil
-				}
-				candidates.Push(p, float64(p.depth))
+// Collapse merges the children of n into n if n is collapsible, and returns
+// whether or not the merge occurred. The merged node holds the objects shared
+// by the children.
+func (n *N) Collapse(data map[id.ID]hyperrectangle.R) bool {
+	if !n.Collapsible(data) {
+		return false
+	}
+	if set := n.children[ChildNE].lookup; len(set) > 0 {
+		n.lookup = set
+	}
+	for x, c := range n.children {
+		c.parent = nil
+		n.children[x] = nil
+	}
+	n.notify(EventMerge)
+	return true
+}
+
+// Compact collapses all collapsible subtrees under n, and returns the number
+// of merged nodes.
+func (n *N) Compact(data map[id.ID]hyperrectangle.R) int {
+	if n.IsLeaf() {
+		return 0
+	}
+	k := 0
+	for _, c := range n.children {
+		k += c.Compact(data)
+	}
+	if n.Collapse(data) {
+		k++
+	}
+	return k
+}
+
+// Grow returns a new root twice the size of n, in which n occupies the input
+// quadrant. The remaining quadrants are new leaves, which hold any objects in
+// the input data which extend past the bounds of n. Unless n is lazy, the new
+// root is collapsed back into a leaf once the objects have been inserted if
+// all of its children are collapsible, e.g. when growing an empty tree.
+//
+// The paths of all nodes under n are prefixed by the input quadrant, and the
+// depth floor of the tree is incremented, which preserves the size of the
+// smallest leaves.
+func (n *N) Grow(c Child, data map[id.ID]hyperrectangle.R) *N {
+	if n.parent != nil {
+		panic("cannot grow a non-root node")
+	}
+
+	xmin, ymin := n.aabb.Min().X(vector.AXIS_X), n.aabb.Min().X(vector.AXIS_Y)
+	xmax, ymax := n.aabb.Max().X(vector.AXIS_X), n.aabb.Max().X(vector.AXIS_Y)
+
+	// Extend the bounds away from the quadrant occupied by n.
+	if c == ChildNE || c == ChildSE {
+		xmin -= xmax - xmin
+	} else {
+		xmax += xmax - xmin
+	}
+	if c == ChildNE || c == ChildNW {
+		ymin -= ymax - ymin
+	} else {
+		ymax += ymax - ymin
+	}
+
+	r := &N{
+		aabb:      *hyperrectangle.New(vector.V{xmin, ymin}, vector.V{xmax, ymax}),
+		tolerance: n.tolerance,
+		floor:     n.floor + 1,
+		policy:    n.policy,
+		lazy:      n.lazy,
+		scratch:   n.scratch,
+		lookup:    map[id.ID]bool{},
+	}
+	r.split(data)
+	r.children[c] = n
+	n.parent, n.corner = r, c
+
+	open := []*N{n}
+	var m *N
+	for len(open) > 0 {
+		m, open = open[0], open[1:]
+		m.depth++
+		m.floor++
+		m.cachePath = Path(m)
+		m.cacheID = ID(m.cachePath)
+		if !m.IsLeaf() {
+			open = append(open, m.children[:]...)
+		}
+	}
+
+	r.Observe(n.hook)
+	r.notify(EventSplit)
+
+	ids := make([]id.ID, 0, len(data))
+	for x, aabb := range data {
+		if !hyperrectangle.Contains(n.aabb, aabb) {
+			ids = append(ids, x)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, x := range ids {
+		for _, s := range r.children {
+			if s != n {
+				s.Insert(x, data)
 			}
 		}
 	}
+
+	if !r.lazy {
+		r.Collapse(data)
+	}
+	return r
+}
+
+// Clone returns a deep copy of the subtree rooted at n, with independent lookup
+// maps and parent pointers. The clone is detached, i.e. its root has no parent, and
+// does not report events until Observe is called. Immutable data, e.g. node
+// bounds and cached paths, is shared with the original.
+func (n *N) Clone() *N {
+	k := 0
+	open := []*N{n}
+	var m *N
+	for len(open) > 0 {
+		m, open = open[len(open)-1], open[:len(open)-1]
+		k++
+		if !m.IsLeaf() {
+			open = append(open, m.children[:]...)
+		}
+	}
+
+	// All nodes are allocated at once, which makes cloning large trees
+	// significantly cheaper, at the cost of retaining the memory of nodes
+	// discarded by later merges until the entire clone is released.
+	slab := make([]N, k)
+	return n.clone(nil, &slab, newScratch())
+}
+
+func (n *N) clone(parent *N, slab *[]N, s *scratch) *N {
+	m := &(*slab)[0]
+	*slab = (*slab)[1:]
+
+	*m = *n
+	m.parent = parent
+	m.hook = nil
+	m.scratch = s
+	// Lookup maps of empty nodes are allocated lazily on Insert.
+	m.lookup = nil
+	if n.IsLeaf() {
+		if len(n.lookup) > 0 {
+			m.lookup = make(map[id.ID]bool, len(n.lookup))
+			for x := range n.lookup {
+				m.lookup[x] = true
+			}
+		}
+		return m
+	}
+
+	for i, c := range n.children {
+		m.children[i] = c.clone(m, slab, s)
+	}
+	return m
+}
+
+// Clear removes all objects under n, and collapses n into an empty leaf. The
+// lookup map of n is reused.
+func (n *N) Clear() {
+	if !n.IsLeaf() {
+		for x, c := range n.children {
+			c.parent = nil
+			n.children[x] = nil
+		}
+		n.notify(EventMerge)
+	}
+	if len(n.lookup) > 0 {
+		for x := range n.lookup {
+			delete(n.lookup, x)
+		}
+		n.notify(EventOccupancy)
+	}
 }
 
 func (n *N) Root() *N {
@@ -391,7 +892,28 @@ func (n *N) Root() *N {
 	return m
 }
 
+// Neighbor is a leaf node adjacent to some reference node, along with the
+// direction of the neighbor relative to the reference node.
+type Neighbor struct {
+	N    *N
+	Edge Edge
+}
+
+// Neighbors returns the leaf nodes adjacent to n.
 func (n *N) Neighbors() []*N {
+	ns := n.NeighborEdges()
+	buf := make([]*N, 0, len(ns))
+	for _, m := range ns {
+		buf = append(buf, m.N)
+	}
+	return buf
+}
+
+// NeighborEdges returns the leaf nodes adjacent to n, tagged with the direction
+// in which the neighbor lies. Neighbors which span multiple directions, e.g. a
+// larger northern neighbor which also covers the northeast corner, are only
+// reported once, with cardinal directions taking precedence over diagonals.
+func (n *N) NeighborEdges() []Neighbor {
 	root := n.Root()
 	p := n.Path()
 
@@ -407,13 +929,13 @@ func (n *N) Neighbors() []*N {
 	paths[EdgeSW] = FSM(paths[EdgeS], EdgeW)
 	paths[EdgeNW] = FSM(paths[EdgeN], EdgeW)
 
-	ns := make([]*N, 0, 16)
+	ns := make([]Neighbor, 0, 16)
 	ids := make(map[string]bool, 16)
 	for e, p := range paths {
 		if len(p) > 0 {
 			for _, m := range Get(root, p).Edge(Edge(e).Invert()) {
 				if _, ok := ids[m.ID()]; !ok {
-					ns = append(ns, m)
+					ns = append(ns, Neighbor{N: m, Edge: Edge(e)})
 				}
 				ids[m.ID()] = true
 			}
@@ -421,3 +943,22 @@ func (n *N) Neighbors() []*N {
 	}
 	return ns
 }
+
+// Portal returns the boundary shared between two adjacent nodes. For nodes
+// which share an edge, the portal is a degenerate AABB spanning the shared
+// line segment; for diagonal neighbors, the portal collapses into the single
+// shared corner.
+//
+// Portal returns false if the input nodes are not adjacent, i.e. if they are
+// disjoint or if one overlaps the interior of the other.
+func Portal(a *N, b *N) (hyperrectangle.R, bool) {
+	r, ok := hyperrectangle.Intersect(a.aabb, b.aabb)
+	if !ok {
+		return hyperrectangle.R{}, false
+	}
+	d := r.D()
+	if d.X(vector.AXIS_X) > 0 && d.X(vector.AXIS_Y) > 0 {
+		return hyperrectangle.R{}, false
+	}
+	return r, true
+}
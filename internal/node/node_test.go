@@ -1,17 +1,20 @@
 package node
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/downflux/go-geometry/nd/hyperrectangle"
 	"github.com/downflux/go-geometry/nd/vector"
 	"github.com/downflux/go-quadtree/id"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 var (
 	opts = []cmp.Option{
 		cmp.AllowUnexported(N{}, hyperrectangle.R{}),
+		cmpopts.IgnoreFields(N{}, "scratch"),
 	}
 )
 
@@ -138,6 +141,64 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestFind(t *testing.T) {
+	root := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 2)
+	root.Insert(100, map[id.ID]hyperrectangle.R{
+		100: *hyperrectangle.New(vector.V{0, 0}, vector.V{1, 1}),
+	})
+
+	type config struct {
+		name string
+		x    string
+		want *N
+	}
+
+	configs := []config{
+		{name: "Root", x: "", want: root},
+		{name: "Child", x: "0", want: root.children[ChildNE]},
+		{name: "Grandchild", x: "21", want: root.children[ChildSW].children[ChildSE]},
+		{name: "PastLeaf", x: "01", want: nil},
+		{name: "Invalid", x: "9", want: nil},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Find(root, c.x); got != c.want {
+				t.Errorf("Find() = %v, want = %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestLess(t *testing.T) {
+	type config struct {
+		name string
+		x    string
+		y    string
+		want bool
+	}
+
+	configs := []config{
+		{name: "Equal", x: "21", y: "21", want: false},
+		{name: "Ancestor", x: "2", y: "21", want: true},
+		{name: "Descendant", x: "21", y: "2", want: false},
+		{name: "SW/SE", x: "2", y: "1", want: true},
+		{name: "SE/NW", x: "1", y: "3", want: true},
+		{name: "NW/NE", x: "3", y: "0", want: true},
+		{name: "NE/SW", x: "0", y: "2", want: false},
+		{name: "Deep", x: "03", y: "10", want: false},
+		{name: "Sentinel", x: "0", y: "$", want: true},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Less(c.x, c.y); got != c.want {
+				t.Errorf("Less() = %v, want = %v", got, c.want)
+			}
+		})
+	}
+}
+
 func TestPath(t *testing.T) {
 	type config struct {
 		name string
@@ -476,6 +537,27 @@ func TestRemove(t *testing.T) {
 				want: want,
 			}
 		}(),
+		func() config {
+			data := map[id.ID]hyperrectangle.R{
+				100: *hyperrectangle.New(vector.V{1, 1}, vector.V{2, 2}),
+				101: *hyperrectangle.New(vector.V{98, 98}, vector.V{99, 99}),
+			}
+
+			n := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 2)
+			n.Insert(100, data)
+			n.Insert(101, data)
+
+			want := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 2)
+			want.Insert(100, data)
+
+			return config{
+				name: "Child/NoCollapse/Internal",
+				n:    n,
+				x:    101,
+				data: data,
+				want: want,
+			}
+		}(),
 	}
 
 	for _, c := range configs {
@@ -800,3 +882,404 @@ func TestNeighbors(t *testing.T) {
 		})
 	}
 }
+
+func TestLeaf(t *testing.T) {
+	root := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 2)
+	root.Insert(100, map[id.ID]hyperrectangle.R{
+		100: *hyperrectangle.New(vector.V{0, 0}, vector.V{1, 1}),
+	})
+
+	type config struct {
+		name string
+		p    vector.V
+		want *N
+	}
+
+	configs := []config{
+		{
+			name: "Outside",
+			p:    vector.V{101, 0},
+			want: nil,
+		},
+		{
+			name: "Large",
+			p:    vector.V{75, 75},
+			want: root.children[ChildNE],
+		},
+		{
+			name: "Small",
+			p:    vector.V{10, 10},
+			want: root.children[ChildSW].children[ChildSW],
+		},
+		{
+			name: "Boundary",
+			p:    vector.V{50, 50},
+			want: root.children[ChildNE],
+		},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			if got := root.Leaf(c.p); got != c.want {
+				t.Errorf("Leaf() = %v, want = %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestNeighborEdges(t *testing.T) {
+	root := &N{}
+	root.children = [4]*N{
+		&N{corner: ChildNE, parent: root, depth: 1, cachePath: []Child{ChildNE}, cacheID: "0"},
+		&N{corner: ChildSE, parent: root, depth: 1, cachePath: []Child{ChildSE}, cacheID: "1"},
+		&N{corner: ChildSW, parent: root, depth: 1, cachePath: []Child{ChildSW}, cacheID: "2"},
+		&N{corner: ChildNW, parent: root, depth: 1, cachePath: []Child{ChildNW}, cacheID: "3"},
+	}
+	root.children[ChildNE].children = [4]*N{
+		&N{corner: ChildNE, parent: root.children[ChildNE], depth: 2, cachePath: []Child{ChildNE, ChildNE}, cacheID: "00"},
+		&N{corner: ChildSE, parent: root.children[ChildNE], depth: 2, cachePath: []Child{ChildNE, ChildSE}, cacheID: "01"},
+		&N{corner: ChildSW, parent: root.children[ChildNE], depth: 2, cachePath: []Child{ChildNE, ChildSW}, cacheID: "02"},
+		&N{corner: ChildNW, parent: root.children[ChildNE], depth: 2, cachePath: []Child{ChildNE, ChildNW}, cacheID: "03"},
+	}
+
+	type config struct {
+		name string
+		n    *N
+		want []Neighbor
+	}
+
+	configs := []config{
+		{
+			name: "Large/SmallNeighbors",
+			n:    root.children[ChildSE],
+			want: []Neighbor{
+				{N: root.children[ChildNE].children[ChildSE], Edge: EdgeN},
+				{N: root.children[ChildNE].children[ChildSW], Edge: EdgeN},
+				{N: root.children[ChildSW], Edge: EdgeW},
+				{N: root.children[ChildNW], Edge: EdgeNW},
+			},
+		},
+		{
+			name: "Small/LargeCorner",
+			n:    root.children[ChildNE].children[ChildSW],
+			want: []Neighbor{
+				{N: root.children[ChildNE].children[ChildNW], Edge: EdgeN},
+				{N: root.children[ChildNE].children[ChildSE], Edge: EdgeE},
+				{N: root.children[ChildSE], Edge: EdgeS},
+				{N: root.children[ChildNW], Edge: EdgeW},
+				{N: root.children[ChildNE].children[ChildNE], Edge: EdgeNE},
+				{N: root.children[ChildSW], Edge: EdgeSW},
+			},
+		},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.n.NeighborEdges()
+			if diff := cmp.Diff(c.want, got, opts...); diff != "" {
+				t.Errorf("NeighborEdges() mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestPortal(t *testing.T) {
+	root := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 2)
+	root.Insert(100, map[id.ID]hyperrectangle.R{
+		100: *hyperrectangle.New(vector.V{0, 0}, vector.V{1, 1}),
+	})
+
+	type config struct {
+		name string
+		a    *N
+		b    *N
+		want hyperrectangle.R
+		ok   bool
+	}
+
+	configs := []config{
+		{
+			name: "Edge",
+			a:    root.children[ChildSW].children[ChildNE],
+			b:    root.children[ChildSE],
+			want: *hyperrectangle.New(vector.V{50, 25}, vector.V{50, 50}),
+			ok:   true,
+		},
+		{
+			name: "Corner",
+			a:    root.children[ChildSW].children[ChildNE],
+			b:    root.children[ChildNE],
+			want: *hyperrectangle.New(vector.V{50, 50}, vector.V{50, 50}),
+			ok:   true,
+		},
+		{
+			name: "Disjoint",
+			a:    root.children[ChildSW].children[ChildSW],
+			b:    root.children[ChildNE],
+			ok:   false,
+		},
+		{
+			name: "Overlap",
+			a:    root.children[ChildSW],
+			b:    root.children[ChildSW].children[ChildSW],
+			ok:   false,
+		},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := Portal(c.a, c.b)
+			if ok != c.ok {
+				t.Fatalf("Portal() = _, %v, want = _, %v", ok, c.ok)
+			}
+			if diff := cmp.Diff(c.want, got, opts...); diff != "" {
+				t.Errorf("Portal() mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestInsertPolicy(t *testing.T) {
+	n := NewWithPolicy(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), func(n *N, aabb hyperrectangle.R) bool {
+		return len(n.Lookup()) > 0
+	}, 2)
+	data := map[id.ID]hyperrectangle.R{
+		1: *hyperrectangle.New(vector.V{10, 10}, vector.V{20, 20}),
+		2: *hyperrectangle.New(vector.V{60, 60}, vector.V{70, 70}),
+	}
+	n.Insert(1, data)
+	if !n.IsLeaf() {
+		t.Fatalf("IsLeaf() = false, want = true")
+	}
+	n.Insert(2, data)
+	if n.IsLeaf() {
+		t.Fatalf("IsLeaf() = true, want = false")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	data := map[id.ID]hyperrectangle.R{
+		1: *hyperrectangle.New(vector.V{0, 0}, vector.V{1, 1}),
+		2: *hyperrectangle.New(vector.V{99, 99}, vector.V{100, 100}),
+	}
+
+	n := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 3)
+	n.SetLazy(true)
+	n.Insert(1, data)
+	n.Insert(2, data)
+	n.Remove(1, data)
+
+	sw := n.children[ChildSW]
+	if c := sw.children[ChildSW]; !c.Collapsible(data) {
+		t.Fatalf("Collapsible() = %v, want = %v", c.Collapsible(data), true)
+	}
+	if sw.Collapsible(data) {
+		t.Fatalf("Collapsible() = %v, want = %v", true, false)
+	}
+	if got, want := n.Compact(data), 2; got != want {
+		t.Errorf("Compact() = %v, want = %v", got, want)
+	}
+	if !sw.IsLeaf() || n.IsLeaf() {
+		t.Errorf("IsLeaf() = %v, %v, want = %v, %v", sw.IsLeaf(), n.IsLeaf(), true, false)
+	}
+}
+
+func TestCollapseCovered(t *testing.T) {
+	data := map[id.ID]hyperrectangle.R{
+		1: *hyperrectangle.New(vector.V{30, 30}, vector.V{31, 31}),
+		2: *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 50}),
+	}
+
+	n := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 6)
+	n.Insert(1, data)
+	n.Insert(2, data)
+
+	if got := n.children[ChildSE]; !got.IsLeaf() || !cmp.Equal(got.lookup, map[id.ID]bool{2: true}) {
+		t.Errorf("IsLeaf() = %v, lookup = %v, want = %v, %v", got.IsLeaf(), got.lookup, true, map[id.ID]bool{2: true})
+	}
+	if n.children[ChildSW].IsLeaf() {
+		t.Fatalf("IsLeaf() = true, want = false")
+	}
+
+	n.Remove(1, data)
+	if got := n.children[ChildSW]; !got.IsLeaf() || !cmp.Equal(got.lookup, map[id.ID]bool{2: true}) {
+		t.Errorf("IsLeaf() = %v, lookup = %v, want = %v, %v", got.IsLeaf(), got.lookup, true, map[id.ID]bool{2: true})
+	}
+
+	// Inserting a new object into the merged leaf splits it again.
+	data[3] = *hyperrectangle.New(vector.V{10, 10}, vector.V{11, 11})
+	n.Insert(3, data)
+	if got := n.children[ChildSW]; got.IsLeaf() {
+		t.Errorf("IsLeaf() = true, want = false")
+	}
+	for _, m := range n.children[ChildSW].Leaves(n.children[ChildSW].AABB()) {
+		if !m.lookup[2] {
+			t.Errorf("lookup[2] = false, want = true")
+		}
+	}
+}
+
+func TestGrow(t *testing.T) {
+	data := map[id.ID]hyperrectangle.R{
+		1: *hyperrectangle.New(vector.V{10, 10}, vector.V{20, 20}),
+		2: *hyperrectangle.New(vector.V{90, 90}, vector.V{110, 110}),
+	}
+
+	n := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 2)
+	n.Insert(1, data)
+	n.Insert(2, data)
+
+	var events []string
+	n.Observe(func(e Event, m *N) { events = append(events, fmt.Sprintf("%v/%v", e, m.ID())) })
+
+	leaf := n.Leaf(vector.V{15, 15})
+	if got, want := leaf.ID(), "22"; got != want {
+		t.Fatalf("ID() = %v, want = %v", got, want)
+	}
+
+	r := n.Grow(ChildSW, data)
+
+	if want := *hyperrectangle.New(vector.V{0, 0}, vector.V{200, 200}); !hyperrectangle.Within(r.AABB(), want) {
+		t.Errorf("AABB() = %v, want = %v", r.AABB(), want)
+	}
+	if got := r.children[ChildSW]; got != n {
+		t.Errorf("children[ChildSW] = %v, want = %v", got, n)
+	}
+	if got, want := leaf.ID(), "222"; got != want {
+		t.Errorf("ID() = %v, want = %v", got, want)
+	}
+	if got, want := leaf.Depth(), 3; got != want {
+		t.Errorf("Depth() = %v, want = %v", got, want)
+	}
+	if got, want := leaf.Floor(), 3; got != want {
+		t.Errorf("Floor() = %v, want = %v", got, want)
+	}
+	if got := r.Leaf(vector.V{15, 15}); got != leaf {
+		t.Errorf("Leaf() = %v, want = %v", got.ID(), leaf.ID())
+	}
+	for _, p := range []vector.V{{105, 105}, {95, 105}, {105, 95}} {
+		if got := r.Leaf(p); !got.lookup[2] {
+			t.Errorf("Leaf(%v).lookup[2] = false, want = true", p)
+		}
+	}
+	if len(events) == 0 || events[0] != "Split/" {
+		t.Errorf("events = %v, want = [Split/ ...]", events)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	type config struct {
+		name    string
+		corrupt func(n *N, data map[id.ID]hyperrectangle.R)
+		want    bool
+	}
+
+	configs := []config{
+		{name: "Valid", corrupt: func(n *N, data map[id.ID]hyperrectangle.R) {}, want: true},
+		{
+			name:    "Internal/Lookup",
+			corrupt: func(n *N, data map[id.ID]hyperrectangle.R) { n.lookup[1] = true },
+		},
+		{
+			name:    "CacheID",
+			corrupt: func(n *N, data map[id.ID]hyperrectangle.R) { n.children[ChildNE].cacheID = "1" },
+		},
+		{
+			name:    "Depth",
+			corrupt: func(n *N, data map[id.ID]hyperrectangle.R) { n.children[ChildNE].depth = 2 },
+		},
+		{
+			name: "Corner",
+			corrupt: func(n *N, data map[id.ID]hyperrectangle.R) {
+				n.children[ChildNE], n.children[ChildSE] = n.children[ChildSE], n.children[ChildNE]
+			},
+		},
+		{
+			name: "Tiling",
+			corrupt: func(n *N, data map[id.ID]hyperrectangle.R) {
+				n.children[ChildNE].aabb = *hyperrectangle.New(vector.V{50, 50}, vector.V{100, 101})
+			},
+		},
+		{
+			name:    "Floor",
+			corrupt: func(n *N, data map[id.ID]hyperrectangle.R) { n.Leaf(vector.V{15, 15}).floor = 1 },
+		},
+		{
+			name:    "Missing",
+			corrupt: func(n *N, data map[id.ID]hyperrectangle.R) { delete(n.Leaf(vector.V{15, 15}).lookup, 1) },
+		},
+		{
+			name:    "Extra",
+			corrupt: func(n *N, data map[id.ID]hyperrectangle.R) { n.Leaf(vector.V{90, 90}).lookup[1] = true },
+		},
+		{
+			name:    "NonExistent",
+			corrupt: func(n *N, data map[id.ID]hyperrectangle.R) { delete(data, 1) },
+		},
+		{
+			name: "Collapsible",
+			corrupt: func(n *N, data map[id.ID]hyperrectangle.R) {
+				for _, l := range n.Leaves(n.aabb) {
+					l.lookup = map[id.ID]bool{}
+				}
+				delete(data, 1)
+			},
+		},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			data := map[id.ID]hyperrectangle.R{
+				1: *hyperrectangle.New(vector.V{10, 10}, vector.V{20, 20}),
+			}
+			n := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 2)
+			n.Insert(1, data)
+
+			c.corrupt(n, data)
+			if err := n.Validate(data, true); (err == nil) != c.want {
+				t.Errorf("Validate() = %v, want ok = %v", err, c.want)
+			}
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	data := map[id.ID]hyperrectangle.R{
+		1: *hyperrectangle.New(vector.V{10, 10}, vector.V{20, 20}),
+		2: *hyperrectangle.New(vector.V{40, 40}, vector.V{60, 60}),
+	}
+
+	n := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 3)
+	n.Insert(1, data)
+	n.Insert(2, data)
+
+	called := false
+	n.Observe(func(e Event, m *N) { called = true })
+
+	m := n.Clone()
+	if err := m.Validate(data, true); err != nil {
+		t.Fatalf("Validate() = %v, want = nil", err)
+	}
+	for _, l := range m.Leaves(m.AABB()) {
+		if o := n.Leaf(center(l.AABB())); o == l || o.ID() != l.ID() || !cmp.Equal(o.IDs(), l.IDs()) {
+			t.Errorf("Leaf(%v) = %v, want = %v", center(l.AABB()), o.ID(), l.ID())
+		}
+	}
+
+	m.Remove(2, data)
+	if err := n.Validate(data, true); err != nil {
+		t.Errorf("Validate() = %v, want = nil", err)
+	}
+	if called {
+		t.Errorf("called = true, want = false")
+	}
+
+	delete(data, 2)
+	if err := m.Validate(data, true); err != nil {
+		t.Errorf("Validate() = %v, want = nil", err)
+	}
+}
+
+func center(r hyperrectangle.R) vector.V {
+	return vector.Add(r.Min(), vector.Scale(0.5, r.D()))
+}
@@ -0,0 +1,136 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+)
+
+// Validate checks the structural invariants of the tree rooted at n against
+// the input object AABBs, and returns an error describing the first violation
+// found. Validate checks that
+//
+//   - the children of each internal node tile the parent exactly,
+//   - the depth, parent, corner, and cached path and ID of each node are
+//     consistent,
+//   - internal nodes do not store any objects,
+//   - no node is deeper than the depth floor,
+//   - every object is stored in exactly the leaves its AABB overlaps, and
+//   - if compact is set, no internal node is collapsible.
+func (n *N) Validate(data map[id.ID]hyperrectangle.R, compact bool) error {
+	if n.parent != nil {
+		return fmt.Errorf("node %q: root has a parent", n.ID())
+	}
+
+	// stored counts the total number of (leaf, object) pairs.
+	stored := 0
+
+	open := []*N{n}
+	var m *N
+	for len(open) > 0 {
+		m, open = open[0], open[1:]
+
+		if m.depth > m.floor {
+			return fmt.Errorf("node %q: depth %v exceeds the floor %v", m.ID(), m.depth, m.floor)
+		}
+		if m.parent != nil {
+			if m.depth != m.parent.depth+1 {
+				return fmt.Errorf("node %q: depth %v does not match the parent depth %v", m.ID(), m.depth, m.parent.depth)
+			}
+			if m.parent.children[m.corner] != m {
+				return fmt.Errorf("node %q: corner %d does not match the parent", m.ID(), uint(m.corner))
+			}
+		} else if m.depth != 0 {
+			return fmt.Errorf("node %q: root has a non-zero depth %v", m.ID(), m.depth)
+		}
+
+		path := Path(m)
+		if len(path) != len(m.cachePath) {
+			return fmt.Errorf("node %q: cached path %v does not match the path %v", m.ID(), m.cachePath, path)
+		}
+		for i := range path {
+			if path[i] != m.cachePath[i] {
+				return fmt.Errorf("node %q: cached path %v does not match the path %v", m.ID(), m.cachePath, path)
+			}
+		}
+		if x := ID(path); x != m.cacheID {
+			return fmt.Errorf("node %q: cached ID does not match the ID %q", m.ID(), x)
+		}
+
+		if m.IsLeaf() {
+			for _, c := range m.children {
+				if c != nil {
+					return fmt.Errorf("node %q: leaf has a partial set of children", m.ID())
+				}
+			}
+			for x := range m.lookup {
+				aabb, ok := data[x]
+				if !ok {
+					return fmt.Errorf("node %q: stores non-existent object %v", m.ID(), x)
+				}
+				if hyperrectangle.Disjoint(aabb, m.aabb) {
+					return fmt.Errorf("node %q: stores object %v which does not overlap the node", m.ID(), x)
+				}
+			}
+			stored += len(m.lookup)
+			continue
+		}
+
+		if len(m.lookup) != 0 {
+			return fmt.Errorf("node %q: internal node stores %v objects", m.ID(), len(m.lookup))
+		}
+		if compact && m.Collapsible(data) {
+			return fmt.Errorf("node %q: internal node is collapsible", m.ID())
+		}
+
+		xmin, ymin := m.aabb.Min().X(vector.AXIS_X), m.aabb.Min().X(vector.AXIS_Y)
+		xmax, ymax := m.aabb.Max().X(vector.AXIS_X), m.aabb.Max().X(vector.AXIS_Y)
+		xmid, ymid := xmin+(xmax-xmin)/2, ymin+(ymax-ymin)/2
+
+		tiles := [4][4]float64{
+			ChildNE: {xmid, ymid, xmax, ymax},
+			ChildSE: {xmid, ymin, xmax, ymid},
+			ChildSW: {xmin, ymin, xmid, ymid},
+			ChildNW: {xmin, ymid, xmid, ymax},
+		}
+		for i, c := range m.children {
+			if c == nil {
+				return fmt.Errorf("node %q: internal node has a partial set of children", m.ID())
+			}
+			if c.parent != m {
+				return fmt.Errorf("node %q: parent does not match", c.ID())
+			}
+			if c.corner != Child(i) {
+				return fmt.Errorf("node %q: corner %d does not match the quadrant %d", c.ID(), uint(c.corner), i)
+			}
+			got := [4]float64{
+				c.aabb.Min().X(vector.AXIS_X), c.aabb.Min().X(vector.AXIS_Y),
+				c.aabb.Max().X(vector.AXIS_X), c.aabb.Max().X(vector.AXIS_Y),
+			}
+			if got != tiles[i] {
+				return fmt.Errorf("node %q: bounds %v do not tile the parent bounds %v", c.ID(), c.aabb, m.aabb)
+			}
+			open = append(open, c)
+		}
+	}
+
+	// Every leaf overlapping an object must store the object. Together with
+	// the per-leaf check above, this ensures objects are stored in exactly
+	// the leaves they overlap.
+	want := 0
+	for x, aabb := range data {
+		for _, l := range n.Leaves(aabb) {
+			if !l.lookup[x] {
+				return fmt.Errorf("node %q: does not store overlapping object %v", l.ID(), x)
+			}
+			want++
+		}
+	}
+	if stored != want {
+		return fmt.Errorf("tree stores %v object references, want %v", stored, want)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package quadtree
+
+import (
+	"sort"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+)
+
+// Len returns the number of objects in the tree.
+func (qt *QT) Len() int { return len(qt.aabb) }
+
+// Has checks if an object with the input ID exists in the tree.
+func (qt *QT) Has(x id.ID) bool {
+	_, ok := qt.aabb[x]
+	return ok
+}
+
+// Get returns a copy of the stored AABB of the input object, and false if the
+// object does not exist.
+func (qt *QT) Get(x id.ID) (hyperrectangle.R, bool) {
+	aabb, ok := qt.aabb[x]
+	if !ok {
+		return hyperrectangle.R{}, false
+	}
+	return clone(aabb), true
+}
+
+// IDs returns the IDs of all objects in the tree, sorted by ID.
+func (qt *QT) IDs() []id.ID {
+	ids := make([]id.ID, 0, len(qt.aabb))
+	for x := range qt.aabb {
+		ids = append(ids, x)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Bounds returns a copy of the bounds of the tree, which may have grown since
+// construction. See SetExpand.
+func (qt *QT) Bounds() hyperrectangle.R { return clone(qt.root.AABB()) }
+
+// clone returns a copy of the input rectangle, which does not share the
+// underlying vectors, i.e. may be safely modified by the caller.
+func clone(r hyperrectangle.R) hyperrectangle.R {
+	buf := hyperrectangle.New(vector.V{0, 0}, vector.V{0, 0}).M()
+	buf.Copy(r)
+	return buf.R()
+}
+
+// Clear removes all objects from the tree, and collapses the tree into a
+// single empty leaf. Clear retains the bounds and configuration of the tree,
+// as well as the allocated capacity of its internal bookkeeping, which makes
+// reusing a tree cheaper than creating a new one. Observers are notified of
+// the collapse of the root.
+func (qt *QT) Clear() {
+	for x := range qt.aabb {
+		delete(qt.aabb, x)
+	}
+	for x := range qt.objects {
+		delete(qt.objects, x)
+	}
+	for n := range qt.since {
+		delete(qt.since, n)
+	}
+
+	if qt.loose != nil {
+		for x := range qt.loose.nodes {
+			delete(qt.loose.nodes, x)
+		}
+		r := qt.loose.root
+		for x := range r.objects {
+			delete(r.objects, x)
+		}
+		r.children = [4]*lnode{}
+		r.count = 0
+	}
+	qt.root.Clear()
+	qt.version++
+}
@@ -0,0 +1,92 @@
+package quadtree
+
+import (
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestAccessors(t *testing.T) {
+	bounds := *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})
+	a := *hyperrectangle.New(vector.V{10, 10}, vector.V{20, 20})
+	b := *hyperrectangle.New(vector.V{60, 60}, vector.V{70, 70})
+
+	type config struct {
+		name string
+		qt   func() *QT
+	}
+
+	configs := []config{
+		{name: "Default", qt: func() *QT { return New(bounds, 0, 3) }},
+		{name: "Loose", qt: func() *QT { return NewLoose(bounds, 2, 3) }},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			qt := c.qt()
+			for x, aabb := range map[id.ID]hyperrectangle.R{2: b, 1: a} {
+				if err := qt.Insert(x, aabb); err != nil {
+					t.Fatalf("Insert() = %v, want = nil", err)
+				}
+			}
+
+			if got, want := qt.Len(), 2; got != want {
+				t.Errorf("Len() = %v, want = %v", got, want)
+			}
+			if !qt.Has(1) || qt.Has(3) {
+				t.Errorf("Has() = %v, %v, want = %v, %v", qt.Has(1), qt.Has(3), true, false)
+			}
+			if diff := cmp.Diff([]id.ID{1, 2}, qt.IDs()); diff != "" {
+				t.Errorf("IDs() mismatch (-want +got):\n%v", diff)
+			}
+			if got := qt.Bounds(); !hyperrectangle.Within(got, bounds) {
+				t.Errorf("Bounds() = %v, want = %v", got, bounds)
+			}
+
+			got, ok := qt.Get(1)
+			if !ok || !hyperrectangle.Within(got, a) {
+				t.Errorf("Get() = %v, %v, want = %v, %v", got, ok, a, true)
+			}
+			// The returned AABB must not alias the stored AABB.
+			got.M().Min().SetX(vector.AXIS_X, 0)
+			if got, _ := qt.Get(1); !hyperrectangle.Within(got, a) {
+				t.Errorf("Get() = %v, want = %v", got, a)
+			}
+			if _, ok := qt.Get(3); ok {
+				t.Errorf("Get() = _, %v, want = _, %v", ok, false)
+			}
+
+			var events []Event
+			cancel := qt.Observe(func(e Event) { events = append(events, e) })
+			defer cancel()
+
+			qt.Clear()
+			if got, want := qt.Len(), 0; got != want {
+				t.Errorf("Len() = %v, want = %v", got, want)
+			}
+			if diff := cmp.Diff([]id.ID{}, qt.IDs(), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("IDs() mismatch (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff([]id.ID{}, qt.Query(bounds, LayerAll), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Query() mismatch (-want +got):\n%v", diff)
+			}
+			if err := qt.Validate(); err != nil {
+				t.Errorf("Validate() = %v, want = nil", err)
+			}
+			if qt.loose == nil && len(events) == 0 {
+				t.Errorf("len(events) = 0, want > 0")
+			}
+
+			if err := qt.Insert(1, a); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+			if err := qt.Validate(); err != nil {
+				t.Errorf("Validate() = %v, want = nil", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package quadtree
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+)
+
+// BoundsPolicy decides how Insert and Update handle objects which extend past
+// the bounds of the tree. Objects which do not overlap the bounds at all are
+// always rejected, unless the tree is set to expand. See SetExpand.
+type BoundsPolicy int
+
+const (
+	// BoundsOverlap accepts objects which partially overlap the bounds of
+	// the tree. Only the portion of the object within the bounds is
+	// reflected in the cells of the tree, but queries and checksums use
+	// the full object AABB. This is the default policy.
+	BoundsOverlap BoundsPolicy = iota
+
+	// BoundsContain rejects objects which are not fully contained within
+	// the bounds of the tree.
+	BoundsContain
+
+	// BoundsClip clips objects to the bounds of the tree, i.e. the stored
+	// object AABB is the intersection of the input AABB and the bounds.
+	BoundsClip
+)
+
+// SetBoundsPolicy configures how objects which extend past the bounds of the
+// tree are handled.
+func (qt *QT) SetBoundsPolicy(p BoundsPolicy) { qt.bounds = p }
+
+// check validates the input AABB, and returns the AABB which should be stored
+// for the object.
+func (qt *QT) check(aabb hyperrectangle.R) (hyperrectangle.R, error) {
+	if aabb.Min().Dimension() != 2 || aabb.Max().Dimension() != 2 {
+		return hyperrectangle.R{}, fmt.Errorf("invalid AABB %v: must be two-dimensional", aabb)
+	}
+	for _, i := range []vector.D{vector.AXIS_X, vector.AXIS_Y} {
+		min, max := aabb.Min().X(i), aabb.Max().X(i)
+		for _, x := range []float64{min, max} {
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				return hyperrectangle.R{}, fmt.Errorf("invalid AABB %v: coordinates must be finite", aabb)
+			}
+		}
+		if min > max {
+			return hyperrectangle.R{}, fmt.Errorf("invalid AABB %v: min must not exceed max", aabb)
+		}
+		if min == max {
+			return hyperrectangle.R{}, fmt.Errorf("invalid AABB %v: must have a positive area", aabb)
+		}
+	}
+
+	if qt.expand && qt.loose == nil {
+		return aabb, nil
+	}
+
+	bounds := qt.root.AABB()
+	if !overlaps(bounds, aabb) {
+		return hyperrectangle.R{}, fmt.Errorf("invalid AABB %v: must overlap the tree bounds %v", aabb, bounds)
+	}
+
+	switch qt.bounds {
+	case BoundsContain:
+		if !hyperrectangle.Contains(bounds, aabb) {
+			return hyperrectangle.R{}, fmt.Errorf("invalid AABB %v: must be within the tree bounds %v", aabb, bounds)
+		}
+	case BoundsClip:
+		r, _ := hyperrectangle.Intersect(bounds, aabb)
+		return r, nil
+	}
+	return aabb, nil
+}
+
+// overlaps checks if the intersection of the input rectangles has a positive
+// area. Unlike hyperrectangle.Intersect, overlaps does not allocate.
+func overlaps(r hyperrectangle.R, s hyperrectangle.R) bool {
+	for _, i := range []vector.D{vector.AXIS_X, vector.AXIS_Y} {
+		if math.Min(r.Max().X(i), s.Max().X(i)) <= math.Max(r.Min().X(i), s.Min().X(i)) {
+			return false
+		}
+	}
+	return true
+}
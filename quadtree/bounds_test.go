@@ -0,0 +1,86 @@
+package quadtree
+
+import (
+	"math"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+)
+
+func TestBoundsPolicy(t *testing.T) {
+	type config struct {
+		name   string
+		policy BoundsPolicy
+		aabb   hyperrectangle.R
+		want   hyperrectangle.R
+		ok     bool
+	}
+
+	inside := *hyperrectangle.New(vector.V{10, 10}, vector.V{20, 20})
+	straddle := *hyperrectangle.New(vector.V{90, 90}, vector.V{110, 110})
+
+	configs := []config{
+		{name: "Inside", policy: BoundsOverlap, aabb: inside, want: inside, ok: true},
+		{name: "NaN", policy: BoundsOverlap, aabb: *hyperrectangle.New(vector.V{math.NaN(), 10}, vector.V{20, 20})},
+		{name: "Inf", policy: BoundsOverlap, aabb: *hyperrectangle.New(vector.V{10, 10}, vector.V{math.Inf(1), 20})},
+		{
+			name:   "Inverted",
+			policy: BoundsOverlap,
+			aabb: func() hyperrectangle.R {
+				r := hyperrectangle.New(vector.V{10, 10}, vector.V{20, 20}).M()
+				r.Min().SetX(vector.AXIS_X, 30)
+				return r.R()
+			}(),
+		},
+		{name: "ZeroArea", policy: BoundsOverlap, aabb: *hyperrectangle.New(vector.V{10, 10}, vector.V{10, 20})},
+		{name: "Empty", policy: BoundsOverlap, aabb: hyperrectangle.R{}},
+		{name: "Outside", policy: BoundsOverlap, aabb: *hyperrectangle.New(vector.V{110, 110}, vector.V{120, 120})},
+		{name: "Touching", policy: BoundsOverlap, aabb: *hyperrectangle.New(vector.V{100, 10}, vector.V{120, 20})},
+		{name: "Overlap/Straddle", policy: BoundsOverlap, aabb: straddle, want: straddle, ok: true},
+		{name: "Contain/Straddle", policy: BoundsContain, aabb: straddle},
+		{name: "Contain/Inside", policy: BoundsContain, aabb: inside, want: inside, ok: true},
+		{
+			name:   "Clip/Straddle",
+			policy: BoundsClip,
+			aabb:   straddle,
+			want:   *hyperrectangle.New(vector.V{90, 90}, vector.V{100, 100}),
+			ok:     true,
+		},
+		{name: "Clip/Outside", policy: BoundsClip, aabb: *hyperrectangle.New(vector.V{110, 110}, vector.V{120, 120})},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 3)
+			qt.SetBoundsPolicy(c.policy)
+
+			err := qt.Insert(1, c.aabb)
+			if ok := err == nil; ok != c.ok {
+				t.Fatalf("Insert() = %v, want ok = %v", err, c.ok)
+			}
+			if !c.ok {
+				if _, ok := qt.aabb[1]; ok {
+					t.Errorf("aabb[1] exists, want not exists")
+				}
+				return
+			}
+			if got := qt.aabb[1]; !hyperrectangle.Within(got, c.want) {
+				t.Errorf("aabb[1] = %v, want = %v", got, c.want)
+			}
+		})
+	}
+
+	t.Run("Update", func(t *testing.T) {
+		qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 3)
+		if err := qt.Insert(1, inside); err != nil {
+			t.Fatalf("Insert() = %v, want = nil", err)
+		}
+		if err := qt.Update(1, *hyperrectangle.New(vector.V{110, 110}, vector.V{120, 120})); err == nil {
+			t.Fatalf("Update() = nil, want != nil")
+		}
+		if got := qt.aabb[1]; !hyperrectangle.Within(got, inside) {
+			t.Errorf("aabb[1] = %v, want = %v", got, inside)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package quadtree
+
+import (
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+type Edge = node.Edge
+
+const (
+	EdgeN  = node.EdgeN
+	EdgeNE = node.EdgeNE
+	EdgeE  = node.EdgeE
+	EdgeSE = node.EdgeSE
+	EdgeS  = node.EdgeS
+	EdgeSW = node.EdgeSW
+	EdgeW  = node.EdgeW
+	EdgeNW = node.EdgeNW
+)
+
+// Cell is a read-only handle to a node in the quadtree. Cells are invalidated
+// when the underlying tree is restructured, i.e. after an Insert or Remove.
+type Cell struct {
+	n *node.N
+}
+
+// Neighbor is an adjacent leaf cell, tagged with the direction in which it
+// lies relative to the reference cell.
+type Neighbor struct {
+	Cell *Cell
+	Edge Edge
+}
+
+func (c *Cell) ID() string             { return c.n.ID() }
+func (c *Cell) AABB() hyperrectangle.R { return clone(c.n.AABB()) }
+func (c *Cell) Depth() int             { return c.n.Depth() }
+func (c *Cell) IsLeaf() bool           { return c.n.IsLeaf() }
+
+// Objects returns the IDs of the objects which overlap the cell. Only leaf
+// cells store objects; internal cells will return an empty list.
+func (c *Cell) Objects() []id.ID { return c.n.IDs() }
+
+// Parent returns the parent cell, or nil if c is the root.
+func (c *Cell) Parent() *Cell {
+	if p := c.n.Parent(); p != nil {
+		return &Cell{n: p}
+	}
+	return nil
+}
+
+// Children returns the child cells of c in NE, SE, SW, NW order, or nil if c
+// is a leaf.
+func (c *Cell) Children() []*Cell {
+	ns := c.n.Children()
+	if ns == nil {
+		return nil
+	}
+	cs := make([]*Cell, 0, len(ns))
+	for _, n := range ns {
+		cs = append(cs, &Cell{n: n})
+	}
+	return cs
+}
+
+// Neighbors returns the leaf cells adjacent to c.
+func (c *Cell) Neighbors() []Neighbor {
+	ns := c.n.NeighborEdges()
+	buf := make([]Neighbor, 0, len(ns))
+	for _, n := range ns {
+		buf = append(buf, Neighbor{
+			Cell: &Cell{n: n.N},
+			Edge: n.Edge,
+		})
+	}
+	return buf
+}
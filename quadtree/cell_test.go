@@ -0,0 +1,92 @@
+package quadtree
+
+import (
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCell(t *testing.T) {
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 1)
+	if err := qt.Insert(100, *hyperrectangle.New(vector.V{0, 0}, vector.V{10, 10})); err != nil {
+		t.Fatalf("Insert() = %v, want = nil", err)
+	}
+
+	if got := qt.CellAt(vector.V{101, 101}); got != nil {
+		t.Errorf("CellAt() = %v, want = nil", got)
+	}
+
+	c := qt.CellAt(vector.V{5, 5})
+	if got, want := c.ID(), "2"; got != want {
+		t.Errorf("ID() = %v, want = %v", got, want)
+	}
+	if diff := cmp.Diff([]id.ID{100}, c.Objects()); diff != "" {
+		t.Errorf("Objects() mismatch (-want +got):\n%v", diff)
+	}
+
+	p := c.Parent()
+	if p == nil || p.Parent() != nil {
+		t.Fatalf("Parent() returned unexpected cell hierarchy")
+	}
+	var ids []string
+	for _, c := range p.Children() {
+		ids = append(ids, c.ID())
+	}
+	if diff := cmp.Diff([]string{"0", "1", "2", "3"}, ids); diff != "" {
+		t.Errorf("Children() mismatch (-want +got):\n%v", diff)
+	}
+
+	type neighbor struct {
+		ID   string
+		Edge Edge
+	}
+	var ns []neighbor
+	for _, n := range c.Neighbors() {
+		ns = append(ns, neighbor{ID: n.Cell.ID(), Edge: n.Edge})
+	}
+	if diff := cmp.Diff([]neighbor{
+		{ID: "3", Edge: EdgeN},
+		{ID: "1", Edge: EdgeE},
+		{ID: "0", Edge: EdgeNE},
+	}, ns); diff != "" {
+		t.Errorf("Neighbors() mismatch (-want +got):\n%v", diff)
+	}
+}
+
+func TestPortal(t *testing.T) {
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 1)
+	if err := qt.Insert(100, *hyperrectangle.New(vector.V{0, 0}, vector.V{10, 10})); err != nil {
+		t.Fatalf("Insert() = %v, want = nil", err)
+	}
+
+	p, ok := qt.CellAt(vector.V{25, 25}).Portal(qt.CellAt(vector.V{75, 25}))
+	if !ok {
+		t.Fatalf("Portal() = _, %v, want = _, %v", ok, true)
+	}
+	if diff := cmp.Diff(Portal{Min: vector.V{50, 0}, Max: vector.V{50, 50}}, p); diff != "" {
+		t.Errorf("Portal() mismatch (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff(vector.V{50, 25}, p.Midpoint()); diff != "" {
+		t.Errorf("Midpoint() mismatch (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff(vector.V{50, 50}, p.Closest(vector.V{100, 100})); diff != "" {
+		t.Errorf("Closest() mismatch (-want +got):\n%v", diff)
+	}
+
+	if _, ok := qt.CellAt(vector.V{25, 25}).Portal(qt.CellAt(vector.V{25, 25})); ok {
+		t.Errorf("Portal() = _, %v, want = _, %v", ok, false)
+	}
+}
+
+func TestCellAABB(t *testing.T) {
+	bounds := *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})
+	qt := New(bounds, 0, 1)
+
+	qt.CellAt(vector.V{50, 50}).AABB().M().Max().SetX(vector.AXIS_Y, 200)
+	if got := qt.root.AABB(); !hyperrectangle.Within(got, bounds) {
+		t.Errorf("AABB() = %v, want = %v", got, bounds)
+	}
+}
@@ -0,0 +1,105 @@
+package quadtree
+
+import (
+	"sort"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+// changes coalesces the tree events which occurred since the last query of a
+// lazily repaired view, e.g. the connected components. Events are reduced to
+// the set of changed cells, where a changed cell subsumes all of its
+// descendants, so the memory footprint is bounded by the size of the tree
+// rather than the number of events. A change to the root, e.g. after the tree
+// grows and all cell IDs shift, is recorded as a full rebuild instead.
+type changes struct {
+	rebuild bool
+	cells   map[string]change
+}
+
+type change struct {
+	aabb hyperrectangle.R
+
+	// structural is set if the cell was split or merged, i.e. if the
+	// cell or any of its descendants may no longer exist.
+	structural bool
+}
+
+func newChanges() *changes { return &changes{cells: map[string]change{}} }
+
+func (cs *changes) observe(e Event) {
+	if cs.rebuild {
+		return
+	}
+	if e.ID == "" {
+		cs.rebuild = true
+		for x := range cs.cells {
+			delete(cs.cells, x)
+		}
+		return
+	}
+	for i := 0; i < len(e.ID); i++ {
+		if _, ok := cs.cells[e.ID[:i]]; ok {
+			return
+		}
+	}
+	c, ok := cs.cells[e.ID]
+	if !ok {
+		c.aabb = e.AABB
+	}
+	c.structural = c.structural || e.Type != EventOccupancy
+	cs.cells[e.ID] = c
+}
+
+func (cs *changes) empty() bool { return !cs.rebuild && len(cs.cells) == 0 }
+
+// ids returns the changed cells in Morton order, which ensures the changes
+// are replayed identically across runs.
+func (cs *changes) ids() []string {
+	ids := make([]string, 0, len(cs.cells))
+	for x := range cs.cells {
+		ids = append(ids, x)
+	}
+	sort.Slice(ids, func(i, j int) bool { return node.Less(ids[i], ids[j]) })
+	return ids
+}
+
+func (cs *changes) reset() {
+	cs.rebuild = false
+	for x := range cs.cells {
+		delete(cs.cells, x)
+	}
+}
+
+// prefixes indexes a multiset of cell IDs by all of their prefixes, which
+// allows the indexed descendants of a cell to be found after the cell has been
+// merged and its children discarded.
+type prefixes map[string]int
+
+func (p prefixes) add(x string) {
+	for i := 0; i <= len(x); i++ {
+		p[x[:i]]++
+	}
+}
+
+func (p prefixes) remove(x string) {
+	for i := 0; i <= len(x); i++ {
+		if p[x[:i]]--; p[x[:i]] <= 0 {
+			delete(p, x[:i])
+		}
+	}
+}
+
+// walk calls f on the input cell and each of its descendants under which at
+// least one ID is indexed. The caller is responsible for checking if the
+// visited cell itself is indexed.
+func (p prefixes) walk(x string, f func(x string)) {
+	if p[x] == 0 {
+		return
+	}
+	f(x)
+	for c := node.ChildNE; c < node.ChildNone; c++ {
+		p.walk(x+c.String(), f)
+	}
+}
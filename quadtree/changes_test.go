@@ -0,0 +1,98 @@
+package quadtree
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChanges(t *testing.T) {
+	type config struct {
+		name    string
+		events  []Event
+		rebuild bool
+		want    []string
+	}
+
+	configs := []config{
+		{
+			name: "Coalesce",
+			events: []Event{
+				{Type: EventOccupancy, ID: "0"},
+				{Type: EventSplit, ID: "0"},
+				{Type: EventOccupancy, ID: "01"},
+				{Type: EventMerge, ID: "0"},
+				{Type: EventOccupancy, ID: "0"},
+			},
+			want: []string{"0"},
+		},
+		{
+			name: "Subsume",
+			events: []Event{
+				{Type: EventOccupancy, ID: "01"},
+				{Type: EventSplit, ID: "2"},
+				{Type: EventOccupancy, ID: "23"},
+			},
+			want: []string{"2", "01"},
+		},
+		{
+			name: "Root",
+			events: []Event{
+				{Type: EventOccupancy, ID: "01"},
+				{Type: EventSplit, ID: ""},
+				{Type: EventOccupancy, ID: "2"},
+			},
+			rebuild: true,
+			want:    []string{},
+		},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			cs := newChanges()
+			for _, e := range c.events {
+				cs.observe(e)
+			}
+			if cs.rebuild != c.rebuild {
+				t.Errorf("rebuild = %v, want = %v", cs.rebuild, c.rebuild)
+			}
+			if diff := cmp.Diff(c.want, cs.ids()); diff != "" {
+				t.Errorf("ids() mismatch (-want +got):\n%v", diff)
+			}
+
+			cs.reset()
+			if !cs.empty() {
+				t.Errorf("empty() = false, want = true")
+			}
+		})
+	}
+}
+
+func TestPrefixes(t *testing.T) {
+	p := prefixes{}
+	for _, x := range []string{"0", "10", "123", "123", "2"} {
+		p.add(x)
+	}
+	p.remove("2")
+
+	walk := func(x string) []string {
+		got := []string{}
+		p.walk(x, func(y string) { got = append(got, y) })
+		sort.Strings(got)
+		return got
+	}
+
+	if diff := cmp.Diff([]string{"1", "10", "12", "123"}, walk("1")); diff != "" {
+		t.Errorf("walk() mismatch (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{}, walk("2")); diff != "" {
+		t.Errorf("walk() mismatch (-want +got):\n%v", diff)
+	}
+
+	p.remove("123")
+	p.remove("123")
+	if diff := cmp.Diff([]string{"1", "10"}, walk("1")); diff != "" {
+		t.Errorf("walk() mismatch (-want +got):\n%v", diff)
+	}
+}
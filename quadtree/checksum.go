@@ -0,0 +1,106 @@
+package quadtree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+// Checksum returns a hash over the bounds and settings of the tree, the stored
+// objects and their properties, and the leaf structure of the tree. For loose
+// trees, the checksum also covers the node in which each object is stored.
+//
+// The settings include the depth floor, the loose factor, the merge delay,
+// the expansion flag, the bounds policy, and the split policy. Split policies
+// are identified by their String method if they implement fmt.Stringer, and
+// otherwise only by their type, i.e. the checksum does not distinguish custom
+// policies of the same type which do not implement fmt.Stringer.
+//
+// The checksum is stable, i.e. two trees which hold the same objects with the
+// same cell layout have the same checksum, regardless of the order in which
+// the objects were added, and across processes and platforms.
+func (qt *QT) Checksum() uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+	u64 := func(v uint64) {
+		binary.LittleEndian.PutUint64(buf, v)
+		h.Write(buf)
+	}
+	f64 := func(v float64) { u64(math.Float64bits(v)) }
+	r := func(r hyperrectangle.R) {
+		for _, v := range []vector.V{r.Min(), r.Max()} {
+			f64(v.X(vector.AXIS_X))
+			f64(v.X(vector.AXIS_Y))
+		}
+	}
+
+	str := func(s string) {
+		u64(uint64(len(s)))
+		h.Write([]byte(s))
+	}
+
+	r(qt.root.AABB())
+	if p, ok := qt.policy.(fmt.Stringer); ok {
+		str(p.String())
+	} else {
+		str(fmt.Sprintf("%T", qt.policy))
+	}
+	u64(uint64(qt.root.Floor()))
+
+	var k float64
+	if qt.loose != nil {
+		k = qt.loose.k
+	}
+	f64(k)
+	u64(uint64(qt.delay))
+	if qt.expand {
+		u64(1)
+	} else {
+		u64(0)
+	}
+	u64(uint64(qt.bounds))
+
+	ids := make([]id.ID, 0, len(qt.aabb))
+	for x := range qt.aabb {
+		ids = append(ids, x)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	u64(uint64(len(ids)))
+	for _, x := range ids {
+		u64(uint64(x))
+		r(qt.aabb[x])
+		f64(qt.objects[x].cost)
+		u64(uint64(qt.objects[x].layer))
+		if qt.loose != nil {
+			str(qt.loose.nodes[x].id)
+		}
+	}
+
+	// The leaves are visited in a fixed order, and each leaf is delimited by
+	// its ID length, which disambiguates the leaf structure.
+	open := []*node.N{qt.root}
+	var n *node.N
+	for len(open) > 0 {
+		n, open = open[len(open)-1], open[:len(open)-1]
+		if !n.IsLeaf() {
+			open = append(open, n.Children()...)
+			continue
+		}
+		str(n.ID())
+		ids := n.IDs()
+		u64(uint64(len(ids)))
+		for _, x := range ids {
+			u64(uint64(x))
+		}
+	}
+
+	return h.Sum64()
+}
@@ -0,0 +1,143 @@
+package quadtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+)
+
+func TestChecksum(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	aabbs := make([]hyperrectangle.R, 20)
+	for i := range aabbs {
+		aabbs[i] = rr(r, 0, 100)
+	}
+
+	build := func(seed int64) *QT {
+		qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 1, 5)
+		for _, i := range rand.New(rand.NewSource(seed)).Perm(len(aabbs)) {
+			if err := qt.Insert(id.ID(i), aabbs[i]); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+		}
+		return qt
+	}
+
+	want := build(0).Checksum()
+
+	t.Run("Stable", func(t *testing.T) {
+		for i := int64(1); i < 5; i++ {
+			if got := build(i).Checksum(); got != want {
+				t.Errorf("Checksum() = %v, want = %v", got, want)
+			}
+		}
+	})
+
+	type config struct {
+		name string
+		f    func(qt *QT) error
+	}
+
+	configs := []config{
+		{
+			name: "Insert",
+			f: func(qt *QT) error {
+				return qt.Insert(100, *hyperrectangle.New(vector.V{90, 90}, vector.V{91, 91}))
+			},
+		},
+		{
+			name: "Remove",
+			f:    func(qt *QT) error { return qt.Remove(0) },
+		},
+		{
+			name: "Update",
+			f: func(qt *QT) error {
+				return qt.Update(0, *hyperrectangle.New(vector.V{90, 90}, vector.V{91, 91}))
+			},
+		},
+		{
+			name: "Cost",
+			f: func(qt *QT) error {
+				if err := qt.Remove(0); err != nil {
+					return err
+				}
+				return qt.Insert(0, aabbs[0], WithCost(2))
+			},
+		},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			qt := build(0)
+			if err := c.f(qt); err != nil {
+				t.Fatalf("f() = %v, want = nil", err)
+			}
+			if got := qt.Checksum(); got == want {
+				t.Errorf("Checksum() = %v, want != %v", got, want)
+			}
+		})
+	}
+
+	t.Run("Settings", func(t *testing.T) {
+		bounds := *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})
+		want := New(bounds, 1, 5).Checksum()
+
+		type config struct {
+			name string
+			qt   func() *QT
+		}
+
+		configs := []config{
+			{name: "Floor", qt: func() *QT { return New(bounds, 1, 6) }},
+			{name: "Tolerance", qt: func() *QT { return New(bounds, 2, 5) }},
+			{
+				name: "SplitPolicy",
+				qt: func() *QT {
+					return NewWithPolicy(bounds, SplitPolicyFunc(func(c *Cell, aabb hyperrectangle.R) bool { return true }), 5)
+				},
+			},
+			{name: "Loose", qt: func() *QT { return NewLoose(bounds, 2, 5) }},
+			{
+				name: "MergeDelay",
+				qt: func() *QT {
+					qt := New(bounds, 1, 5)
+					qt.SetMergeDelay(4)
+					return qt
+				},
+			},
+			{
+				name: "Expand",
+				qt: func() *QT {
+					qt := New(bounds, 1, 5)
+					qt.SetExpand(true)
+					return qt
+				},
+			},
+			{
+				name: "BoundsPolicy",
+				qt: func() *QT {
+					qt := New(bounds, 1, 5)
+					qt.SetBoundsPolicy(BoundsClip)
+					return qt
+				},
+			},
+		}
+
+		for _, c := range configs {
+			t.Run(c.name, func(t *testing.T) {
+				if got := c.qt().Checksum(); got == want {
+					t.Errorf("Checksum() = %v, want != %v", got, want)
+				}
+			})
+		}
+
+		t.Run("LooseFactor", func(t *testing.T) {
+			if a, b := NewLoose(bounds, 2, 5).Checksum(), NewLoose(bounds, 3, 5).Checksum(); a == b {
+				t.Errorf("Checksum() = %v, want != %v", a, b)
+			}
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package quadtree
+
+import (
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+// Clone returns a fully independent copy of the tree, including its objects,
+// cells, and configuration. Cloning copies the existing structure directly,
+// and is therefore much cheaper than rebuilding the tree via Insert.
+//
+// Observers, planners, and other views registered on the original tree are
+// not carried over to the clone.
+func (qt *QT) Clone() *QT {
+	c := &QT{
+		root:    qt.root.Clone(),
+		policy:  qt.policy,
+		aabb:    make(map[id.ID]hyperrectangle.R, len(qt.aabb)),
+		objects: make(map[id.ID]object, len(qt.objects)),
+		version: qt.version,
+		delay:   qt.delay,
+		expand:  qt.expand,
+		bounds:  qt.bounds,
+	}
+	c.root.Observe(c.notify)
+
+	for x, aabb := range qt.aabb {
+		buf := hyperrectangle.New(vector.V{0, 0}, vector.V{0, 0}).M()
+		buf.Copy(aabb)
+		c.aabb[x] = buf.R()
+	}
+	for x, o := range qt.objects {
+		c.objects[x] = o
+	}
+
+	if qt.since != nil {
+		c.since = make(map[*node.N]uint64, len(qt.since))
+		for n, v := range qt.since {
+			// Nodes under a previously collapsed ancestor are detached
+			// from the tree, and are not tracked by the clone.
+			if n.Root() != qt.root {
+				continue
+			}
+			c.since[node.Find(c.root, n.ID())] = v
+		}
+	}
+
+	if qt.loose != nil {
+		c.loose = qt.loose.clone()
+	}
+
+	return c
+}
+
+func (l *loose) clone() *loose {
+	c := &loose{
+		k:     l.k,
+		floor: l.floor,
+		nodes: make(map[id.ID]*lnode, len(l.nodes)),
+	}
+	c.root = l.root.clone(nil, c.nodes)
+	return c
+}
+
+// clone returns a deep copy of the subtree rooted at n, and records the new
+// node of each stored object in the input map.
+func (n *lnode) clone(parent *lnode, nodes map[id.ID]*lnode) *lnode {
+	m := &lnode{}
+	*m = *n
+	m.parent = parent
+	m.objects = make(map[id.ID]bool, len(n.objects))
+	for x := range n.objects {
+		m.objects[x] = true
+		nodes[x] = m
+	}
+	for i, c := range n.children {
+		if c != nil {
+			m.children[i] = c.clone(m, nodes)
+		}
+	}
+	return m
+}
@@ -0,0 +1,86 @@
+package quadtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClone(t *testing.T) {
+	bounds := *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})
+
+	type config struct {
+		name string
+		opts []Option
+	}
+
+	configs := []config{
+		{name: "Default", opts: []Option{WithFloor(5), WithTolerance(1)}},
+		{name: "Capacity", opts: []Option{WithFloor(5), WithSplitPolicy(Capacity(2))}},
+		{name: "Delayed", opts: []Option{WithFloor(5), WithMergeDelay(3)}},
+		{name: "Loose", opts: []Option{WithFloor(5), WithLoose(2)}},
+	}
+
+	// churn applies n random operations to the input tree.
+	churn := func(t *testing.T, r *rand.Rand, qt *QT, n int) {
+		for i := 0; i < n; i++ {
+			if err := mutate(r, qt, nil); err != nil {
+				t.Fatalf("mutate() = %v, want = nil", err)
+			}
+		}
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(0))
+
+			qt, err := NewWithOptions(bounds, c.opts...)
+			if err != nil {
+				t.Fatalf("NewWithOptions() = %v, want = nil", err)
+			}
+			churn(t, r, qt, 50)
+
+			observed := false
+			cancel := qt.Observe(func(e Event) { observed = true })
+			defer cancel()
+
+			clone := qt.Clone()
+			if err := clone.Validate(); err != nil {
+				t.Fatalf("Validate() = %v, want = nil", err)
+			}
+			if got, want := clone.Checksum(), qt.Checksum(); got != want {
+				t.Errorf("Checksum() = %v, want = %v", got, want)
+			}
+			s, g := vector.V{1, 1}, vector.V{99, 99}
+			if diff := cmp.Diff(qt.Path(s, g), clone.Path(s, g)); diff != "" {
+				t.Errorf("Path() mismatch (-want +got):\n%v", diff)
+			}
+
+			// Modifying the clone must not affect the original.
+			want := qt.Checksum()
+			churn(t, r, clone, 50)
+			if got := qt.Checksum(); got != want {
+				t.Errorf("Checksum() = %v, want = %v", got, want)
+			}
+			if err := qt.Validate(); err != nil {
+				t.Errorf("Validate() = %v, want = nil", err)
+			}
+			if err := clone.Validate(); err != nil {
+				t.Errorf("Validate() = %v, want = nil", err)
+			}
+			if observed {
+				t.Errorf("observed = true, want = false")
+			}
+
+			// Modifying the original must not affect the clone.
+			want = clone.Checksum()
+			churn(t, r, qt, 50)
+			if got := clone.Checksum(); got != want {
+				t.Errorf("Checksum() = %v, want = %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package quadtree
+
+import (
+	"sort"
+
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+// components tracks the connected regions of passable leaves in the tree.
+// Two passable leaves are connected if they share an edge, i.e. the
+// connectivity matches the moves allowed by Path. Only objects on the
+// collision layers of the mask are considered when deciding if a leaf is
+// passable.
+//
+// Labels are repaired lazily on the next query. Only the components which
+// overlap a changed cell are relabeled; all other leaves keep their existing
+// labels.
+type components struct {
+	qt   *QT
+	mask Layer
+
+	init    bool
+	next    int
+	changes *changes
+
+	// labels maps the ID of each passable leaf to its component. Labeled
+	// IDs are indexed by prefix, which allows the stale labels under a
+	// merged cell to be found.
+	labels  map[string]int
+	index   prefixes
+	members map[int][]string
+}
+
+func (cs *components) label(p vector.V) (int, bool) {
+	n := cs.qt.root.Leaf(p)
+	if n == nil {
+		return 0, false
+	}
+	cs.repair()
+	c, ok := cs.labels[n.ID()]
+	return c, ok
+}
+
+func (cs *components) set(x string, c int) {
+	if _, ok := cs.labels[x]; !ok {
+		cs.index.add(x)
+	}
+	cs.labels[x] = c
+}
+
+func (cs *components) unset(x string) {
+	if _, ok := cs.labels[x]; ok {
+		cs.index.remove(x)
+		delete(cs.labels, x)
+	}
+}
+
+func (cs *components) repair() {
+	if cs.init && cs.changes.empty() {
+		return
+	}
+
+	var seeds []*node.N
+	if !cs.init || cs.changes.rebuild {
+		cs.init = true
+		for x := range cs.labels {
+			delete(cs.labels, x)
+		}
+		for x := range cs.index {
+			delete(cs.index, x)
+		}
+		for c := range cs.members {
+			delete(cs.members, c)
+		}
+		seeds = cs.qt.root.Leaves(cs.qt.root.AABB())
+	} else {
+		affected := map[int]bool{}
+		for _, x := range cs.changes.ids() {
+			cs.index.walk(x, func(y string) {
+				if c, ok := cs.labels[y]; ok {
+					affected[c] = true
+				}
+			})
+			seeds = append(seeds, cs.qt.root.Leaves(cs.changes.cells[x].aabb)...)
+		}
+		// Relabel the affected components in a fixed order, which
+		// ensures component IDs are assigned identically across runs.
+		labels := make([]int, 0, len(affected))
+		for c := range affected {
+			labels = append(labels, c)
+		}
+		sort.Ints(labels)
+		for _, c := range labels {
+			for _, x := range cs.members[c] {
+				if n := node.Find(cs.qt.root, x); n != nil && n.IsLeaf() {
+					seeds = append(seeds, n)
+				}
+				cs.unset(x)
+			}
+			delete(cs.members, c)
+		}
+	}
+	cs.changes.reset()
+
+	gr := cs.qt.graph(nil, nil, pathOptions{mask: cs.mask})
+	for _, n := range seeds {
+		if _, ok := cs.labels[n.ID()]; ok || gr.blocked(n) {
+			continue
+		}
+
+		c := cs.next
+		cs.next++
+
+		var members []string
+		open := []*node.N{n}
+		cs.set(n.ID(), c)
+		for len(open) > 0 {
+			var m *node.N
+			m, open = open[0], open[1:]
+			members = append(members, m.ID())
+			for _, l := range gr.neighbors(m) {
+				d, ok := cs.labels[l.ID()]
+				if ok && d == c {
+					continue
+				}
+				if _, _, ok := gr.cost(m, l); !ok {
+					continue
+				}
+				// Absorb any untouched component which is now
+				// reachable through the changed cells.
+				if ok {
+					for _, x := range cs.members[d] {
+						cs.labels[x] = c
+					}
+					members = append(members, cs.members[d]...)
+					delete(cs.members, d)
+					continue
+				}
+				cs.set(l.ID(), c)
+				open = append(open, l)
+			}
+		}
+		cs.members[c] = members
+	}
+}
+
+func (qt *QT) components(mask Layer) *components {
+	if cs, ok := qt.cs[mask]; ok {
+		return cs
+	}
+	if qt.cs == nil {
+		qt.cs = map[Layer]*components{}
+	}
+	cs := &components{
+		qt:      qt,
+		mask:    mask,
+		changes: newChanges(),
+		labels:  map[string]int{},
+		index:   prefixes{},
+		members: map[int][]string{},
+	}
+	qt.Observe(cs.changes.observe)
+	qt.cs[mask] = cs
+	return cs
+}
+
+// ComponentID returns a label for the connected region of passable space
+// containing the input point. Two points share a label if and only if a path
+// exists between them. ComponentID returns false if the point lies outside the
+// tree or in an impassable cell.
+//
+// Only objects on the collision layers of the input mask are considered, i.e.
+// the labels match the connectivity of Path with WithMask. The tree keeps the
+// labels of each queried mask up to date until the tree is discarded.
+//
+// Labels are only stable until the tree is next modified.
+func (qt *QT) ComponentID(p vector.V, mask Layer) (int, bool) {
+	return qt.components(mask).label(p)
+}
+
+// Connected checks if a path exists between the two input points, i.e. if the
+// points lie in the same connected region of passable space. Only objects on
+// the collision layers of the input mask are considered.
+func (qt *QT) Connected(a vector.V, b vector.V, mask Layer) bool {
+	cs := qt.components(mask)
+	c, ok := cs.label(a)
+	if !ok {
+		return false
+	}
+	d, ok := cs.label(b)
+	return ok && c == d
+}
@@ -0,0 +1,96 @@
+package quadtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+)
+
+func TestConnected(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+
+	// walls generates objects which frequently partition the map.
+	walls := func(r *rand.Rand) hyperrectangle.R {
+		if c := 10 + r.Float64()*80; r.Intn(3) == 0 {
+			return *hyperrectangle.New(vector.V{c, 0}, vector.V{c + 2, 100})
+		} else if r.Intn(3) == 0 {
+			return *hyperrectangle.New(vector.V{0, c}, vector.V{100, c + 2})
+		}
+		return rr(r, 0, 100)
+	}
+
+	for i := 0; i < 10; i++ {
+		qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 1, 5)
+
+		for j := 0; j < 40; j++ {
+			if err := mutate(r, qt, walls); err != nil {
+				t.Fatalf("mutate() = %v, want = nil", err)
+			}
+
+			for k := 0; k < 5; k++ {
+				a := vector.V{r.Float64() * 100, r.Float64() * 100}
+				b := vector.V{r.Float64() * 100, r.Float64() * 100}
+
+				want := qt.Path(a, b).Path != nil
+				if got := qt.Connected(a, b, LayerAll); got != want {
+					t.Fatalf("[%v, %v]: Connected(%v, %v) = %v, want = %v", i, j, a, b, got, want)
+				}
+
+				ca, oka := qt.ComponentID(a, LayerAll)
+				cb, okb := qt.ComponentID(b, LayerAll)
+				if got := oka && okb && ca == cb; got != want {
+					t.Fatalf("[%v, %v]: ComponentID(%v) == ComponentID(%v) = %v, want = %v", i, j, a, b, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestConnectedMask(t *testing.T) {
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 5)
+	if err := qt.Insert(1, *hyperrectangle.New(vector.V{49, 0}, vector.V{51, 100}), WithLayer(1<<1)); err != nil {
+		t.Fatalf("Insert() = %v, want = nil", err)
+	}
+	s, g := vector.V{25, 50}, vector.V{75, 50}
+
+	type config struct {
+		name string
+		mask Layer
+		want bool
+	}
+
+	configs := []config{
+		{name: "All", mask: LayerAll, want: false},
+		{name: "Blocking", mask: 1 << 1, want: false},
+		{name: "Ignore", mask: 1 << 2, want: true},
+		{name: "None", mask: LayerNone, want: true},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			if got := qt.Connected(s, g, c.mask); got != c.want {
+				t.Errorf("Connected() = %v, want = %v", got, c.want)
+			}
+			if got := qt.Path(s, g, WithMask(c.mask)).Path != nil; got != c.want {
+				t.Errorf("Path() != nil = %v, want = %v", got, c.want)
+			}
+			// The cached labels of the mask are reused by nearest
+			// substitution.
+			if got := qt.Path(s, g, WithMask(c.mask), WithNearest()).Substituted; got == c.want {
+				t.Errorf("Substituted = %v, want = %v", got, !c.want)
+			}
+		})
+	}
+
+	// Labels of each mask are repaired independently.
+	if err := qt.Remove(1); err != nil {
+		t.Fatalf("Remove() = %v, want = nil", err)
+	}
+	for _, c := range configs {
+		if !qt.Connected(s, g, c.mask) {
+			t.Errorf("[%v]: Connected() = false, want = true", c.name)
+		}
+	}
+}
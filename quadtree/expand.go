@@ -0,0 +1,58 @@
+package quadtree
+
+import (
+	"fmt"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+// SetExpand configures whether or not the tree grows to fit objects inserted
+// outside of its bounds. By default, the bounds are fixed, and only the
+// portion of an object within the bounds is stored.
+//
+// The tree grows by re-rooting, i.e. the old root becomes a quadrant of a new
+// root twice its size, extended towards the inserted object, until the object
+// fits. The IDs of all existing cells gain a leading quadrant digit, and the
+// depth floor is incremented, which preserves the size of the smallest cells.
+// Observers are notified of a split of the new root.
+//
+// Loose trees never grow.
+func (qt *QT) SetExpand(expand bool) { qt.expand = expand }
+
+// grow re-roots the tree until it fully contains the input AABB, which must
+// have finite coordinates.
+func (qt *QT) grow(aabb hyperrectangle.R) error {
+	for !hyperrectangle.Contains(qt.root.AABB(), aabb) {
+		if hyperrectangle.V(qt.root.AABB()) == 0 {
+			return fmt.Errorf("cannot expand a tree with degenerate bounds %v", qt.root.AABB())
+		}
+
+		p, c := center(aabb), center(qt.root.AABB())
+		west := p.X(vector.AXIS_X) < c.X(vector.AXIS_X)
+		north := p.X(vector.AXIS_Y) >= c.X(vector.AXIS_Y)
+
+		// The old root occupies the quadrant opposite to the direction
+		// in which the tree needs to grow.
+		var q node.Child
+		switch {
+		case west && north:
+			q = node.ChildSE
+		case west:
+			q = node.ChildNE
+		case north:
+			q = node.ChildSW
+		default:
+			q = node.ChildNW
+		}
+		qt.root = qt.root.Grow(q, qt.aabb)
+
+		// Lazy trees do not collapse the new root, which is instead
+		// merged once the delay expires.
+		if qt.since != nil && qt.root.Collapsible(qt.aabb) {
+			qt.since[qt.root] = qt.version
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package quadtree
+
+import (
+	"math"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExpand(t *testing.T) {
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 3)
+	qt.SetExpand(true)
+
+	if err := qt.Insert(1, *hyperrectangle.New(vector.V{10, 10}, vector.V{20, 20})); err != nil {
+		t.Fatalf("Insert() = %v, want = nil", err)
+	}
+	before := qt.CellAt(vector.V{15, 15}).ID()
+
+	s, g := vector.V{90, 5}, vector.V{350, 390}
+	p := qt.Planner(s, g)
+	defer p.Close()
+	if got := p.Path(); got != nil {
+		t.Fatalf("Path() = %v, want = nil", got)
+	}
+
+	aabb := *hyperrectangle.New(vector.V{300, 300}, vector.V{320, 320})
+	if err := qt.Insert(2, aabb); err != nil {
+		t.Fatalf("Insert() = %v, want = nil", err)
+	}
+
+	if want := *hyperrectangle.New(vector.V{0, 0}, vector.V{400, 400}); !hyperrectangle.Within(qt.root.AABB(), want) {
+		t.Errorf("AABB() = %v, want = %v", qt.root.AABB(), want)
+	}
+	if got, want := qt.CellAt(vector.V{15, 15}).ID(), "22"+before; got != want {
+		t.Errorf("ID() = %v, want = %v", got, want)
+	}
+	if diff := cmp.Diff([]id.ID{2}, qt.Query(aabb, LayerAll)); diff != "" {
+		t.Errorf("Query() mismatch (-want +got):\n%v", diff)
+	}
+
+	want := qt.Path(s, g)
+	if want.Path == nil {
+		t.Fatalf("Path() = nil, want != nil")
+	}
+	if diff := cmp.Diff(want.Path, p.Path()); diff != "" {
+		t.Errorf("Path() mismatch (-want +got):\n%v", diff)
+	}
+	if !qt.Connected(s, g, LayerAll) {
+		t.Errorf("Connected() = false, want = true")
+	}
+
+	t.Run("NonFinite", func(t *testing.T) {
+		if err := qt.Insert(3, *hyperrectangle.New(vector.V{0, 0}, vector.V{math.Inf(1), 1})); err == nil {
+			t.Errorf("Insert() = nil, want != nil")
+		}
+	})
+}
+
+func TestExpandEmpty(t *testing.T) {
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{10, 10}), 0, 3)
+	qt.SetExpand(true)
+
+	if err := qt.Insert(1, *hyperrectangle.New(vector.V{100, 100}, vector.V{101, 101})); err != nil {
+		t.Fatalf("Insert() = %v, want = nil", err)
+	}
+	if err := qt.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want = nil", err)
+	}
+}
@@ -0,0 +1,84 @@
+package quadtree
+
+import (
+	"github.com/downflux/go-geometry/nd/vector"
+)
+
+// Field is a distance field over the leaves of the tree, which records the
+// cost of the shortest path from each passable leaf to the nearest source.
+//
+// Distances are measured between leaf centers, and leaves containing a source
+// have a distance of 0. The field is computed once, and is reused across reads
+// until the tree is modified, at which point it is lazily recomputed.
+type Field struct {
+	qt      *QT
+	sources []vector.V
+	o       pathOptions
+
+	version uint64
+	dist    map[string]float64
+}
+
+// DistanceField returns the distance field from the input source points. Only
+// the WithMask and WithEdgeCost options affect the field.
+func (qt *QT) DistanceField(sources []vector.V, opts ...PathOption) *Field {
+	f := &Field{
+		qt:      qt,
+		sources: make([]vector.V, 0, len(sources)),
+		o:       newPathOptions(opts),
+	}
+	for _, s := range sources {
+		f.sources = append(f.sources, vector.V{s.X(vector.AXIS_X), s.X(vector.AXIS_Y)})
+	}
+	f.compute()
+	return f
+}
+
+func (f *Field) compute() {
+	f.version = f.qt.version
+	f.dist = map[string]float64{}
+
+	gr := f.qt.graph(nil, nil, f.o)
+
+	var s *search
+	for _, p := range f.sources {
+		n := f.qt.root.Leaf(p)
+		if n == nil || gr.blocked(n) {
+			continue
+		}
+		if s == nil {
+			s = gr.newSearch(n, nil, nil)
+		} else {
+			s.seed(n)
+		}
+	}
+	if s == nil {
+		return
+	}
+	s.step(0)
+
+	for n := range s.closed {
+		f.dist[n.ID()] = s.costs[n]
+	}
+}
+
+// At returns the distance of the leaf containing the input point to the
+// nearest source. At returns false if the point is outside the tree, or if
+// no source is reachable from the point.
+func (f *Field) At(p vector.V) (float64, bool) {
+	n := f.qt.root.Leaf(p)
+	if n == nil {
+		return 0, false
+	}
+	return f.Distance(&Cell{n: n})
+}
+
+// Distance returns the distance of the input leaf cell to the nearest source.
+// Distance returns false if no source is reachable from the cell.
+func (f *Field) Distance(c *Cell) (float64, bool) {
+	if f.version != f.qt.version {
+		f.compute()
+	}
+	d, ok := f.dist[c.ID()]
+	return d, ok
+}
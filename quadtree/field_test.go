@@ -0,0 +1,50 @@
+package quadtree
+
+import (
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+)
+
+func TestDistanceField(t *testing.T) {
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 1)
+	if err := qt.Insert(1, *hyperrectangle.New(vector.V{1, 1}, vector.V{49, 49})); err != nil {
+		t.Fatalf("Insert() = %v, want = nil", err)
+	}
+
+	f := qt.DistanceField([]vector.V{{10, 90}, {-10, -10}})
+
+	type config struct {
+		name string
+		p    vector.V
+		want float64
+		ok   bool
+	}
+
+	configs := []config{
+		{name: "Source", p: vector.V{25, 75}, want: 0, ok: true},
+		{name: "Adjacent", p: vector.V{75, 75}, want: 50, ok: true},
+		{name: "Corner", p: vector.V{75, 25}, want: 100, ok: true},
+		{name: "Blocked", p: vector.V{25, 25}, ok: false},
+		{name: "OutOfBounds", p: vector.V{125, 25}, ok: false},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := f.At(c.p)
+			if ok != c.ok || got != c.want {
+				t.Errorf("At() = %v, %v, want = %v, %v", got, ok, c.want, c.ok)
+			}
+		})
+	}
+
+	t.Run("Invalidate", func(t *testing.T) {
+		if err := qt.Remove(1); err != nil {
+			t.Fatalf("Remove() = %v, want = nil", err)
+		}
+		if got, ok := f.At(vector.V{25, 25}); !ok || got != 0 {
+			t.Errorf("At() = %v, %v, want = %v, %v", got, ok, 0, true)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package quadtree
+
+import (
+	"math"
+
+	"github.com/downflux/go-geometry/nd/vector"
+)
+
+// Heuristic estimates the cost of moving between two points. Path only
+// guarantees an optimal path if the heuristic never overestimates the true
+// cost.
+type Heuristic interface {
+	Heuristic(a vector.V, b vector.V) float64
+}
+
+// EdgeCost prices moving between two adjacent, passable leaf cells. Costs must
+// be non-negative. Returning +Inf, NaN, or a negative cost forbids the move.
+type EdgeCost interface {
+	Cost(from *Cell, to *Cell) float64
+}
+
+type HeuristicFunc func(a vector.V, b vector.V) float64
+
+func (f HeuristicFunc) Heuristic(a vector.V, b vector.V) float64 { return f(a, b) }
+
+type EdgeCostFunc func(from *Cell, to *Cell) float64
+
+func (f EdgeCostFunc) Cost(from *Cell, to *Cell) float64 { return f(from, to) }
+
+var (
+	// Euclidean is the straight-line distance between two points.
+	Euclidean = HeuristicFunc(func(a vector.V, b vector.V) float64 {
+		return distance(a, b)
+	})
+
+	// Manhattan is the sum of the axis-aligned distances between two
+	// points.
+	Manhattan = HeuristicFunc(func(a vector.V, b vector.V) float64 {
+		d := vector.Sub(a, b)
+		return math.Abs(d.X(vector.AXIS_X)) + math.Abs(d.X(vector.AXIS_Y))
+	})
+
+	// Octile is the distance between two points when only axis-aligned and
+	// diagonal moves are allowed.
+	Octile = HeuristicFunc(func(a vector.V, b vector.V) float64 {
+		d := vector.Sub(a, b)
+		dx, dy := math.Abs(d.X(vector.AXIS_X)), math.Abs(d.X(vector.AXIS_Y))
+		return math.Max(dx, dy) + float64((math.Sqrt2-1)*math.Min(dx, dy))
+	})
+
+	// Zero disables the heuristic, i.e. turns A* into Dijkstra's
+	// algorithm.
+	Zero = HeuristicFunc(func(a vector.V, b vector.V) float64 { return 0 })
+)
+
+// WithHeuristic overrides the search heuristic. By default, Path uses the
+// Euclidean distance, scaled by the cheapest cost multiplier of any object in
+// the tree, or Zero if a custom edge cost is set.
+func WithHeuristic(h Heuristic) PathOption {
+	return func(o *pathOptions) { o.heuristic = h }
+}
+
+// WithEdgeCost overrides the cost of moving between adjacent leaves. Leaves
+// overlapped by impassable objects remain impassable. By default, moving
+// between leaves is priced as the distance travelled between the leaf centers
+// via the midpoint of the shared portal, scaled by the cost multiplier of each
+// leaf.
+//
+// Setting an edge cost disables the default heuristic, which may overestimate
+// custom costs, e.g. a flat per-move penalty, and cause Path to return
+// suboptimal paths. Callers may speed up the search by also supplying a
+// heuristic which never overestimates the custom cost.
+func WithEdgeCost(c EdgeCost) PathOption {
+	return func(o *pathOptions) { o.edge = c }
+}
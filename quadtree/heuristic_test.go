@@ -0,0 +1,125 @@
+package quadtree
+
+import (
+	"math"
+	"testing"
+
+	"github.com/downflux/go-geometry/epsilon"
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHeuristic(t *testing.T) {
+	type config struct {
+		name string
+		h    Heuristic
+		a    vector.V
+		b    vector.V
+		want float64
+	}
+
+	configs := []config{
+		{name: "Euclidean", h: Euclidean, a: vector.V{0, 0}, b: vector.V{3, 4}, want: 5},
+		{name: "Manhattan", h: Manhattan, a: vector.V{0, 0}, b: vector.V{3, -4}, want: 7},
+		{name: "Octile", h: Octile, a: vector.V{0, 0}, b: vector.V{3, 4}, want: 4 + 3*(math.Sqrt2-1)},
+		{name: "Zero", h: Zero, a: vector.V{0, 0}, b: vector.V{3, 4}, want: 0},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.h.Heuristic(c.a, c.b); !epsilon.Within(got, c.want) {
+				t.Errorf("Heuristic() = %v, want = %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestPathOptions(t *testing.T) {
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 2)
+	if err := qt.Insert(1, *hyperrectangle.New(vector.V{1, 1}, vector.V{24, 24})); err != nil {
+		t.Fatalf("Insert() = %v, want = nil", err)
+	}
+	s, g := vector.V{10, 90}, vector.V{90, 10}
+
+	t.Run("Heuristic", func(t *testing.T) {
+		want := qt.Path(s, g)
+		got := qt.Path(s, g, WithHeuristic(Zero))
+		if got.Cost != want.Cost {
+			t.Errorf("Cost = %v, want = %v", got.Cost, want.Cost)
+		}
+		if got.Expanded <= want.Expanded {
+			t.Errorf("Expanded = %v, want > %v", got.Expanded, want.Expanded)
+		}
+	})
+
+	t.Run("EdgeCost", func(t *testing.T) {
+		// Forbid moving through the northeast quadrant.
+		danger := *hyperrectangle.New(vector.V{50, 50}, vector.V{100, 100})
+		got := qt.Path(s, g, WithEdgeCost(EdgeCostFunc(func(from *Cell, to *Cell) float64 {
+			if hyperrectangle.Contains(danger, to.AABB()) {
+				return math.Inf(1)
+			}
+			return Euclidean(center(from.AABB()), center(to.AABB()))
+		})))
+		if got.Path == nil {
+			t.Fatalf("Path() = nil, want a non-nil path")
+		}
+		for _, x := range got.Cells {
+			if x[0] == '0' {
+				t.Errorf("Cells = %v, want no cells in the northeast quadrant", got.Cells)
+			}
+		}
+		if diff := cmp.Diff(g, got.Goal); diff != "" {
+			t.Errorf("Goal mismatch (-want +got):\n%v", diff)
+		}
+	})
+
+	t.Run("EdgeCost/Negative", func(t *testing.T) {
+		// Negative costs are treated as forbidden moves, i.e. the
+		// path must avoid the northeast quadrant.
+		got := qt.Path(s, g, WithEdgeCost(EdgeCostFunc(func(from *Cell, to *Cell) float64 {
+			if to.ID()[0] == '0' {
+				return -1
+			}
+			return 1
+		})))
+		if got.Path == nil {
+			t.Fatalf("Path() = nil, want a non-nil path")
+		}
+		for _, x := range got.Cells {
+			if x[0] == '0' {
+				t.Errorf("Cells = %v, want no cells in the northeast quadrant", got.Cells)
+			}
+		}
+
+		p := qt.Planner(s, g, WithEdgeCost(EdgeCostFunc(func(from *Cell, to *Cell) float64 { return -1 })))
+		defer p.Close()
+		if got := p.Path(); got != nil {
+			t.Errorf("Path() = %v, want = nil", got)
+		}
+	})
+
+	t.Run("EdgeCost/Hop", func(t *testing.T) {
+		// Small obstacles along a horizontal line create many small
+		// leaves, which are cheaper to route around when each move
+		// costs the same. The default heuristic would greedily follow
+		// the line, as it overestimates the remaining number of moves.
+		qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 6)
+		for i := 0; i < 8; i++ {
+			x := float64(10 + 10*i)
+			if err := qt.Insert(id.ID(i), *hyperrectangle.New(vector.V{x, 50}, vector.V{x + 0.5, 50.5})); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+		}
+		s, g := vector.V{1, 49}, vector.V{99, 49}
+		hop := WithEdgeCost(EdgeCostFunc(func(from *Cell, to *Cell) float64 { return 1 }))
+
+		want := qt.Path(s, g, hop, WithHeuristic(Zero))
+		got := qt.Path(s, g, hop)
+		if got.Cost != want.Cost {
+			t.Errorf("Cost = %v, want = %v", got.Cost, want.Cost)
+		}
+	})
+}
@@ -0,0 +1,461 @@
+package quadtree
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+const (
+	// abstractSrc and abstractDst are the abstract graph keys of the
+	// source and goal points. These never collide with cell IDs, which only
+	// consist of digits.
+	abstractSrc = "^"
+	abstractDst = "$"
+
+	// wide is the number of portals at which an entrance is represented by
+	// a transition at each end instead of a single transition in the
+	// middle.
+	//
+	// See Botea, Muller, and Schaeffer 2004 for more information.
+	wide = 6
+)
+
+// Hierarchy is a hierarchical path planner modeled after HPA*. The nodes of the
+// tree at a fixed depth partition the map into clusters; leaves shallower
+// than the cluster depth form their own cluster.
+//
+// The border between two adjacent clusters is split into entrances, i.e.
+// maximal runs of contiguous passable portals, and each entrance is
+// represented by one or two transitions. The shortest paths between all
+// transitions of a cluster are precomputed and cached, and a coarse search is
+// run over the resulting abstract graph. The coarse path is refined by
+// splicing together the cached paths, and only the source and goal clusters
+// are searched on each call.
+//
+// Cached clusters are invalidated as the tree changes, along with the adjacent
+// clusters which share a border with the changed cluster. The precomputation
+// is only amortized over repeated queries; a single query on a fresh Hierarchy
+// is slower than Path. Paths returned by the Hierarchy are not guaranteed to be
+// optimal.
+//
+// See Botea, Muller, and Schaeffer 2004 for more information.
+type Hierarchy struct {
+	qt     *QT
+	depth  int
+	o      pathOptions
+	cancel func()
+
+	// clusters maps the ID of a cluster to its precomputed transitions.
+	clusters map[string]cluster
+
+	// adjacent maps the ID of a cluster to the cached clusters which
+	// share a border with the cluster.
+	adjacent map[string]map[string]bool
+
+	// index indexes the keys of both clusters and adjacent by prefix,
+	// which allows the clusters under a split or merged cell to be found.
+	index prefixes
+}
+
+// cluster is the abstract graph of a single cluster, and maps the ID of each
+// transition leaf in the cluster to its edges in the abstract graph.
+//
+// Cached leaves remain valid until the cluster is invalidated, as any
+// structural change to the cluster or to an adjacent cluster invalidates the
+// cluster.
+type cluster map[string]*transition
+
+type transition struct {
+	n *node.N
+
+	// links are the edges to the paired transitions in the adjacent
+	// clusters.
+	links []link
+
+	// routes maps the other transitions of the cluster to the shortest path
+	// within the cluster.
+	routes map[string]route
+}
+
+type link struct {
+	n *node.N
+	c float64
+}
+
+// route is a cached path within a cluster. The chain lists the leaves along
+// the path, excluding the first leaf.
+type route struct {
+	c     float64
+	chain []*node.N
+}
+
+// Hierarchy returns a new hierarchical planner which clusters the tree at the
+// input depth. The planner subscribes to changes in the tree until Close is
+// called.
+func (qt *QT) Hierarchy(depth int, opts ...PathOption) (*Hierarchy, error) {
+	if depth < 0 {
+		return nil, fmt.Errorf("invalid cluster depth %v", depth)
+	}
+
+	h := &Hierarchy{
+		qt:       qt,
+		depth:    depth,
+		o:        newPathOptions(opts),
+		clusters: map[string]cluster{},
+		index:    prefixes{},
+		adjacent: map[string]map[string]bool{},
+	}
+	h.cancel = qt.Observe(h.observe)
+	return h, nil
+}
+
+// Close unsubscribes the planner from the tree. The planner may not be used
+// after Close is called.
+func (h *Hierarchy) Close() { h.cancel() }
+
+func (h *Hierarchy) observe(e Event) {
+	x := e.ID
+	if len(x) > h.depth {
+		x = x[:h.depth]
+	}
+	var stale []string
+	h.index.walk(x, func(y string) { stale = append(stale, y) })
+	for _, y := range stale {
+		h.invalidate(y)
+		// The transitions of the adjacent clusters depend on the
+		// shared border.
+		if zs, ok := h.adjacent[y]; ok {
+			for z := range zs {
+				h.invalidate(z)
+			}
+			delete(h.adjacent, y)
+			h.index.remove(y)
+		}
+	}
+}
+
+func (h *Hierarchy) invalidate(c string) {
+	if _, ok := h.clusters[c]; ok {
+		delete(h.clusters, c)
+		h.index.remove(c)
+	}
+}
+
+// cluster returns the ID of the cluster containing the input leaf.
+func (h *Hierarchy) cluster(n *node.N) string {
+	if x := n.ID(); len(x) > h.depth {
+		return x[:h.depth]
+	}
+	return n.ID()
+}
+
+func within(c string) func(n *node.N) bool {
+	return func(n *node.N) bool { return strings.HasPrefix(n.ID(), c) }
+}
+
+// portal is a passable connection across the border of a cluster, from the
+// leaf m inside the cluster to the leaf l outside of the cluster.
+type portal struct {
+	m *node.N
+	l *node.N
+	r hyperrectangle.R
+}
+
+// transitions returns the transitions of the input cluster, i.e. the pairs of
+// leaves across the border of the cluster which represent each entrance.
+//
+// Entrances are derived only from the portals along the shared border, and
+// therefore the adjacent cluster chooses the same transitions from the other
+// side of the border.
+func (h *Hierarchy) transitions(gr graph, c string) []portal {
+	f := within(c)
+	n := node.Find(h.qt.root, c)
+
+	borders := map[string][]portal{}
+	for _, m := range n.Leaves(n.AABB()) {
+		for _, l := range gr.neighbors(m) {
+			if f(l) {
+				continue
+			}
+			// Changes to any adjacent cluster may open or close
+			// entrances along the shared border.
+			k := h.cluster(l)
+			if h.adjacent[k] == nil {
+				h.adjacent[k] = map[string]bool{}
+				h.index.add(k)
+			}
+			h.adjacent[k][c] = true
+
+			r, ok := node.Portal(m, l)
+			if !ok || vector.Within(r.Min(), r.Max()) {
+				continue
+			}
+			if _, _, ok := gr.cost(m, l); !ok {
+				continue
+			}
+			borders[k] = append(borders[k], portal{m: m, l: l, r: r})
+		}
+	}
+
+	ks := make([]string, 0, len(borders))
+	for k := range borders {
+		ks = append(ks, k)
+	}
+	sort.Slice(ks, func(i, j int) bool { return node.Less(ks[i], ks[j]) })
+
+	// connected checks if two leaves on the same side of the border may
+	// be traversed consecutively.
+	connected := func(a *node.N, b *node.N) bool {
+		if a == b {
+			return true
+		}
+		_, _, ok := gr.cost(a, b)
+		return ok
+	}
+
+	var ts []portal
+	for _, k := range ks {
+		ps := borders[k]
+
+		// The border between two clusters is a single axis-aligned
+		// segment.
+		axis := vector.AXIS_X
+		if ps[0].r.Min().X(vector.AXIS_X) == ps[0].r.Max().X(vector.AXIS_X) {
+			axis = vector.AXIS_Y
+		}
+		sort.Slice(ps, func(i, j int) bool { return ps[i].r.Min().X(axis) < ps[j].r.Min().X(axis) })
+
+		for i := 0; i < len(ps); {
+			j := i + 1
+			for j < len(ps) && ps[j].r.Min().X(axis) <= ps[j-1].r.Max().X(axis) && connected(ps[j-1].m, ps[j].m) && connected(ps[j-1].l, ps[j].l) {
+				j++
+			}
+			if j-i < wide {
+				ts = append(ts, ps[(i+j)/2])
+			} else {
+				ts = append(ts, ps[i], ps[j-1])
+			}
+			i = j
+		}
+	}
+	return ts
+}
+
+// abstract returns the precomputed abstract graph of the input cluster.
+func (h *Hierarchy) abstract(gr graph, c string) cluster {
+	if a, ok := h.clusters[c]; ok {
+		return a
+	}
+
+	// Transitions are independent of the source and goal points.
+	gr.src, gr.dst = nil, nil
+
+	a := cluster{}
+	var leaves []*node.N
+	for _, p := range h.transitions(gr, c) {
+		t, ok := a[p.m.ID()]
+		if !ok {
+			t = &transition{n: p.m, routes: map[string]route{}}
+			a[p.m.ID()] = t
+			leaves = append(leaves, p.m)
+		}
+		_, d, _ := gr.cost(p.m, p.l)
+		t.links = append(t.links, link{n: p.l, c: d})
+	}
+
+	f := within(c)
+	for i, m := range leaves {
+		// The default cost is symmetric, and the routes to the
+		// preceding transitions were found by their own searches.
+		targets := leaves
+		if gr.edge == nil {
+			targets = leaves[i+1:]
+		}
+		s := gr.newSearch(m, nil, f)
+		reach(s, targets)
+
+		for _, l := range targets {
+			if l == m || !s.closed[l] {
+				continue
+			}
+			chain := trace(s.parents, l)
+			a[m.ID()].routes[l.ID()] = route{c: s.costs[l], chain: chain[1:]}
+			if gr.edge == nil {
+				rev := make([]*node.N, 0, len(chain)-1)
+				for j := len(chain) - 2; j >= 0; j-- {
+					rev = append(rev, chain[j])
+				}
+				a[l.ID()].routes[m.ID()] = route{c: s.costs[l], chain: rev}
+			}
+		}
+	}
+
+	h.clusters[c] = a
+	h.index.add(c)
+	return a
+}
+
+// reach runs the input search until all target leaves are closed, or until no
+// further leaves are reachable.
+func reach(s *search, targets []*node.N) {
+	for _, t := range targets {
+		for !s.closed[t] {
+			if _, done := s.step(1); done && !s.closed[t] {
+				break
+			}
+		}
+	}
+}
+
+// Path returns a list of waypoints from s to g which avoids all impassable
+// objects in the tree, or nil if no such path exists.
+func (h *Hierarchy) Path(s vector.V, g vector.V) []vector.V {
+	gr := h.qt.graph(s, g, h.o)
+	if gr.src == nil || gr.dst == nil || gr.blocked(gr.src) || gr.blocked(gr.dst) {
+		return nil
+	}
+
+	cs, cd := h.cluster(gr.src), h.cluster(gr.dst)
+
+	// transitions returns the transition leaves of the input cluster in
+	// Morton order, which ensures the searches below are deterministic.
+	transitions := func(c string) []*node.N {
+		a := h.abstract(gr, c)
+		ns := make([]*node.N, 0, len(a))
+		for _, t := range a {
+			ns = append(ns, t.n)
+		}
+		sort.Slice(ns, func(i, j int) bool { return node.Less(ns[i].ID(), ns[j].ID()) })
+		return ns
+	}
+
+	// Connect the source and goal points to the transitions of their
+	// respective clusters. The searches stop once all transitions are
+	// reached, and do not expand leaves outside of the clusters.
+	ssrc := gr.newSearch(gr.src, nil, within(cs))
+	if ts := transitions(cs); cs == cd {
+		reach(ssrc, append(ts, gr.dst))
+	} else {
+		reach(ssrc, ts)
+	}
+	sdst := gr.newSearch(gr.dst, nil, within(cd))
+	reach(sdst, transitions(cd))
+
+	type edge struct {
+		x string
+		n *node.N
+		c float64
+	}
+	successors := func(x string, n *node.N) []edge {
+		var es []edge
+		if x == abstractSrc {
+			for y, t := range h.abstract(gr, cs) {
+				if ssrc.closed[t.n] {
+					es = append(es, edge{x: y, n: t.n, c: ssrc.costs[t.n]})
+				}
+			}
+			if cs == cd && ssrc.closed[gr.dst] {
+				es = append(es, edge{x: abstractDst, n: gr.dst, c: ssrc.costs[gr.dst]})
+			}
+			return es
+		}
+
+		k := h.cluster(n)
+		t, ok := h.abstract(gr, k)[x]
+		if !ok {
+			return nil
+		}
+		for y, r := range t.routes {
+			es = append(es, edge{x: y, n: r.chain[len(r.chain)-1], c: r.c})
+		}
+		for _, l := range t.links {
+			es = append(es, edge{x: l.n.ID(), n: l.n, c: l.c})
+		}
+		if k == cd && sdst.closed[n] {
+			es = append(es, edge{x: abstractDst, n: gr.dst, c: sdst.costs[n]})
+		}
+		return es
+	}
+	estimate := func(x string, n *node.N) float64 {
+		switch x {
+		case abstractSrc:
+			return gr.estimate(gr.src, gr.dst)
+		case abstractDst:
+			return 0
+		default:
+			return gr.estimate(n, gr.dst)
+		}
+	}
+
+	costs := map[string]float64{abstractSrc: 0}
+	parents := map[string]string{}
+	leaves := map[string]*node.N{abstractSrc: gr.src}
+	closed := map[string]bool{}
+
+	open := &queue{}
+	heap.Push(open, item{x: abstractSrc, k: key{estimate(abstractSrc, gr.src), 0}})
+	for open.Len() > 0 {
+		x := heap.Pop(open).(item).x
+		if closed[x] {
+			continue
+		}
+		closed[x] = true
+		if x == abstractDst {
+			break
+		}
+
+		for _, e := range successors(x, leaves[x]) {
+			if closed[e.x] {
+				continue
+			}
+			c := costs[x] + e.c
+			if d, ok := costs[e.x]; ok && d <= c {
+				continue
+			}
+			costs[e.x] = c
+			parents[e.x] = x
+			leaves[e.x] = e.n
+			heap.Push(open, item{x: e.x, k: key{c + estimate(e.x, e.n), c}})
+		}
+	}
+	if !closed[abstractDst] {
+		return nil
+	}
+
+	var coarse []string
+	for x := abstractDst; x != abstractSrc; x = parents[x] {
+		coarse = append(coarse, x)
+	}
+	coarse = append(coarse, abstractSrc)
+
+	// Refine the coarse path, which is stored in reverse order, by
+	// splicing together the cached routes between transitions.
+	var chain []*node.N
+	for i := len(coarse) - 1; i > 0; i-- {
+		u, v := coarse[i], coarse[i-1]
+		switch {
+		case u == abstractSrc:
+			chain = trace(ssrc.parents, leaves[v])
+		case v == abstractDst:
+			rev := trace(sdst.parents, leaves[u])
+			for j := len(rev) - 2; j >= 0; j-- {
+				chain = append(chain, rev[j])
+			}
+		default:
+			n := leaves[u]
+			if r, ok := h.abstract(gr, h.cluster(n))[u].routes[v]; ok {
+				chain = append(chain, r.chain...)
+			} else {
+				chain = append(chain, leaves[v])
+			}
+		}
+	}
+
+	return gr.waypoints(chain)
+}
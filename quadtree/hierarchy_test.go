@@ -0,0 +1,101 @@
+package quadtree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHierarchy(t *testing.T) {
+	if _, err := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 1, 5).Hierarchy(-1); err == nil {
+		t.Errorf("Hierarchy() = _, nil, want a non-nil error")
+	}
+
+	r := rand.New(rand.NewSource(0))
+
+	for i := 0; i < 10; i++ {
+		qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 1, 5)
+		h, err := qt.Hierarchy(2)
+		if err != nil {
+			t.Fatalf("Hierarchy() = _, %v, want = _, nil", err)
+		}
+
+		for j := 0; j < 30; j++ {
+			if err := mutate(r, qt, nil); err != nil {
+				t.Fatalf("mutate() = %v, want = nil", err)
+			}
+
+			s := vector.V{r.Float64() * 100, r.Float64() * 100}
+			g := vector.V{r.Float64() * 100, r.Float64() * 100}
+
+			want := qt.Path(s, g).Path
+			got := h.Path(s, g)
+			if (want == nil) != (got == nil) {
+				t.Fatalf("[%v, %v]: Path() = %v, want = %v", i, j, got, want)
+			}
+			if got == nil {
+				continue
+			}
+			if diff := cmp.Diff([]vector.V{s, g}, []vector.V{got[0], got[len(got)-1]}); diff != "" {
+				t.Errorf("[%v, %v]: Path() endpoints mismatch (-want +got):\n%v", i, j, diff)
+			}
+			for k := 1; k < len(got)-1; k++ {
+				// Objects with a finite cost multiplier are
+				// passable.
+				if c := qt.CellAt(got[k]); c == nil || math.IsInf(qt.weight(c.n, LayerAll), 1) {
+					t.Errorf("[%v, %v]: Path() waypoint %v is not in a passable cell", i, j, got[k])
+				}
+			}
+		}
+		h.Close()
+	}
+}
+
+// BenchmarkHierarchy compares the flat and hierarchical planners over a path
+// across a 4096 x 4096 map with 3000 small obstacles, i.e. ~134k leaves. Cold
+// calls include precomputing the transitions of every visited cluster; warm
+// calls reuse the cached clusters.
+func BenchmarkHierarchy(b *testing.B) {
+	r := rand.New(rand.NewSource(0))
+
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{4096, 4096}), 0, 10)
+	for i := 0; i < 3000; i++ {
+		x, y := r.Float64()*4096, r.Float64()*4096
+		w, h := 1+r.Float64()*32, 1+r.Float64()*32
+		if err := qt.Insert(id.ID(i), *hyperrectangle.New(vector.V{x, y}, vector.V{x + w, y + h})); err != nil {
+			b.Fatalf("Insert() = %v, want = nil", err)
+		}
+	}
+	s, g := vector.V{1, 1}, vector.V{4095, 4095}
+
+	b.Run("Path", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			qt.Path(s, g)
+		}
+	})
+	for _, depth := range []int{3, 4, 5} {
+		b.Run(fmt.Sprintf("Hierarchy/Depth=%v/Cold", depth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				h, _ := qt.Hierarchy(depth)
+				h.Path(s, g)
+				h.Close()
+			}
+		})
+		b.Run(fmt.Sprintf("Hierarchy/Depth=%v/Warm", depth), func(b *testing.B) {
+			h, _ := qt.Hierarchy(depth)
+			defer h.Close()
+			h.Path(s, g)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				h.Path(s, g)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package quadtree
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+// loose is a loose quadtree, which stores each object exactly once, in the
+// deepest node whose loose bounds, i.e. the node bounds scaled by a constant
+// factor about the node center, fully contain the object. Because sibling
+// loose bounds overlap, small moves rarely change the node an object is stored
+// in.
+//
+// Nodes are created on demand, and empty subtrees are discarded.
+type loose struct {
+	root  *lnode
+	k     float64
+	floor int
+
+	// nodes tracks the node in which each object is stored.
+	nodes map[id.ID]*lnode
+}
+
+type lnode struct {
+	id     string
+	depth  int
+	parent *lnode
+
+	// aabb is the tight bounds of the node, and bounds the loose bounds.
+	aabb   hyperrectangle.R
+	bounds hyperrectangle.R
+
+	children [4]*lnode
+	objects  map[id.ID]bool
+
+	// count is the total number of objects stored in the subtree rooted at
+	// this node.
+	count int
+}
+
+// checkLoose validates the input loose factor.
+func checkLoose(k float64) error {
+	if !(k >= 1) || math.IsInf(k, 1) {
+		return fmt.Errorf("invalid loose factor %v: must be finite and at least 1", k)
+	}
+	return nil
+}
+
+func newLoose(aabb hyperrectangle.R, k float64, floor int) *loose {
+	return &loose{
+		root:  newLNode(nil, "", aabb, k),
+		k:     k,
+		floor: floor,
+		nodes: make(map[id.ID]*lnode, 128),
+	}
+}
+
+func newLNode(parent *lnode, x string, aabb hyperrectangle.R, k float64) *lnode {
+	n := &lnode{
+		id:      x,
+		parent:  parent,
+		aabb:    aabb,
+		bounds:  scale(aabb, k),
+		objects: map[id.ID]bool{},
+	}
+	if parent != nil {
+		n.depth = parent.depth + 1
+	}
+	return n
+}
+
+// quadrant returns the tight bounds of the input child quadrant of r.
+func quadrant(r hyperrectangle.R, c node.Child) hyperrectangle.R {
+	min, max := r.Min(), r.Max()
+	mid := center(r)
+
+	xmin, xmid, xmax := min.X(vector.AXIS_X), mid.X(vector.AXIS_X), max.X(vector.AXIS_X)
+	ymin, ymid, ymax := min.X(vector.AXIS_Y), mid.X(vector.AXIS_Y), max.X(vector.AXIS_Y)
+
+	switch c {
+	case node.ChildNE:
+		return *hyperrectangle.New(vector.V{xmid, ymid}, vector.V{xmax, ymax})
+	case node.ChildSE:
+		return *hyperrectangle.New(vector.V{xmid, ymin}, vector.V{xmax, ymid})
+	case node.ChildSW:
+		return *hyperrectangle.New(vector.V{xmin, ymin}, vector.V{xmid, ymid})
+	default:
+		return *hyperrectangle.New(vector.V{xmin, ymid}, vector.V{xmid, ymax})
+	}
+}
+
+// target returns the node in which the input AABB should be stored, along
+// with the quadrants which need to be created below the deepest existing node
+// on the way to the target.
+func (l *loose) target(aabb hyperrectangle.R) (*lnode, []node.Child) {
+	n := l.root
+	var missing []node.Child
+
+	// b tracks the tight bounds of the current (possibly missing) node.
+	b := n.aabb
+	for depth := 0; depth < l.floor; depth++ {
+		mid, p := center(b), center(aabb)
+
+		var c node.Child
+		switch e, north := p.X(vector.AXIS_X) >= mid.X(vector.AXIS_X), p.X(vector.AXIS_Y) >= mid.X(vector.AXIS_Y); {
+		case e && north:
+			c = node.ChildNE
+		case e:
+			c = node.ChildSE
+		case north:
+			c = node.ChildNW
+		default:
+			c = node.ChildSW
+		}
+
+		q := quadrant(b, c)
+		if !hyperrectangle.Contains(scale(q, l.k), aabb) {
+			break
+		}
+		b = q
+
+		if len(missing) == 0 && n.children[c] != nil {
+			n = n.children[c]
+		} else {
+			missing = append(missing, c)
+		}
+	}
+	return n, missing
+}
+
+func (l *loose) insert(x id.ID, aabb hyperrectangle.R) {
+	n, missing := l.target(aabb)
+	for _, c := range missing {
+		n.children[c] = newLNode(n, n.id+c.String(), quadrant(n.aabb, c), l.k)
+		n = n.children[c]
+	}
+
+	n.objects[x] = true
+	l.nodes[x] = n
+	for m := n; m != nil; m = m.parent {
+		m.count++
+	}
+}
+
+func (l *loose) remove(x id.ID) {
+	n := l.nodes[x]
+	delete(n.objects, x)
+	delete(l.nodes, x)
+
+	for m := n; m != nil; m = m.parent {
+		m.count--
+		if m.count == 0 && m.parent != nil {
+			m.parent.children[m.id[len(m.id)-1]-'0'] = nil
+		}
+	}
+}
+
+// update moves the input object to a new AABB. The tree is not modified if the
+// object remains in the same node.
+func (l *loose) update(x id.ID, aabb hyperrectangle.R) {
+	if n, missing := l.target(aabb); len(missing) == 0 && n == l.nodes[x] {
+		return
+	}
+	l.remove(x)
+	l.insert(x, aabb)
+}
+
+// query returns the IDs of all objects stored in nodes whose loose bounds
+// overlap the query rectangle. The caller is responsible for filtering the
+// candidates against the exact object AABBs.
+func (l *loose) query(q hyperrectangle.R) []id.ID {
+	var ids []id.ID
+	open := []*lnode{l.root}
+	var n *lnode
+	for len(open) > 0 {
+		n, open = open[len(open)-1], open[:len(open)-1]
+		if n.parent != nil && hyperrectangle.Disjoint(q, n.bounds) {
+			continue
+		}
+		for x := range n.objects {
+			ids = append(ids, x)
+		}
+		for _, c := range n.children {
+			if c != nil {
+				open = append(open, c)
+			}
+		}
+	}
+	return ids
+}
+
+// scale returns the input rectangle scaled by a factor of k about its center.
+func scale(r hyperrectangle.R, k float64) hyperrectangle.R {
+	c := center(r)
+	d := vector.Scale(k/2, r.D())
+	return *hyperrectangle.New(vector.Sub(c, d), vector.Add(c, d))
+}
@@ -0,0 +1,136 @@
+package quadtree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestLoose(t *testing.T) {
+	bounds := *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})
+
+	t.Run("Target", func(t *testing.T) {
+		type config struct {
+			name string
+			aabb hyperrectangle.R
+			want string
+		}
+
+		configs := []config{
+			{name: "Small", aabb: *hyperrectangle.New(vector.V{1, 1}, vector.V{2, 2}), want: "2222"},
+			{name: "Boundary", aabb: *hyperrectangle.New(vector.V{49, 49}, vector.V{51, 51}), want: "0222"},
+			{name: "Large", aabb: *hyperrectangle.New(vector.V{10, 10}, vector.V{90, 90}), want: ""},
+			{name: "Straddle", aabb: *hyperrectangle.New(vector.V{-10, -10}, vector.V{5, 5}), want: "22"},
+		}
+
+		for _, c := range configs {
+			t.Run(c.name, func(t *testing.T) {
+				qt := NewLoose(bounds, 2, 4)
+				if err := qt.Insert(1, c.aabb); err != nil {
+					t.Fatalf("Insert() = %v, want = nil", err)
+				}
+				if got := qt.loose.nodes[1].id; got != c.want {
+					t.Errorf("id = %v, want = %v", got, c.want)
+				}
+			})
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		qt := NewLoose(bounds, 2, 4)
+		if err := qt.Insert(1, *hyperrectangle.New(vector.V{1, 1}, vector.V{2, 2})); err != nil {
+			t.Fatalf("Insert() = %v, want = nil", err)
+		}
+		n := qt.loose.nodes[1]
+		if err := qt.Update(1, *hyperrectangle.New(vector.V{1.5, 1.5}, vector.V{2.5, 2.5})); err != nil {
+			t.Fatalf("Update() = %v, want = nil", err)
+		}
+		if got := qt.loose.nodes[1]; got != n {
+			t.Errorf("nodes[1] = %v, want = %v", got.id, n.id)
+		}
+
+		if err := qt.Update(1, *hyperrectangle.New(vector.V{90, 90}, vector.V{91, 91})); err != nil {
+			t.Fatalf("Update() = %v, want = nil", err)
+		}
+		if got, want := qt.loose.nodes[1].id, "0002"; got != want {
+			t.Errorf("id = %v, want = %v", got, want)
+		}
+		if got := qt.root.IsLeaf(); !got {
+			t.Errorf("IsLeaf() = %v, want = %v", got, true)
+		}
+		for i, c := range qt.loose.root.children {
+			if c != nil && i != 0 {
+				t.Errorf("children[%v] = %v, want = nil", i, c.id)
+			}
+		}
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		r := rand.New(rand.NewSource(0))
+
+		qt := NewLoose(bounds, 2, 6)
+		for i := 0; i < 200; i++ {
+			if err := qt.Insert(id.ID(i), rr(r, 0, 100)); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+		}
+		for i := 0; i < 200; i += 2 {
+			if err := qt.Update(id.ID(i), rr(r, 0, 100)); err != nil {
+				t.Fatalf("Update() = %v, want = nil", err)
+			}
+		}
+		for i := 0; i < 200; i += 3 {
+			if err := qt.Remove(id.ID(i)); err != nil {
+				t.Fatalf("Remove() = %v, want = nil", err)
+			}
+		}
+
+		for i := 0; i < 20; i++ {
+			q := rr(r, 0, 100)
+			var want []id.ID
+			for j := 0; j < 200; j++ {
+				if aabb, ok := qt.aabb[id.ID(j)]; ok && !hyperrectangle.Disjoint(q, aabb) {
+					want = append(want, id.ID(j))
+				}
+			}
+			if diff := cmp.Diff(want, qt.Query(q, LayerAll), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Query() mismatch (-want +got):\n%v", diff)
+			}
+		}
+	})
+}
+
+func TestNewLoose(t *testing.T) {
+	bounds := *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})
+
+	type config struct {
+		name string
+		k    float64
+		ok   bool
+	}
+
+	configs := []config{
+		{name: "One", k: 1, ok: true},
+		{name: "Two", k: 2, ok: true},
+		{name: "Tight", k: 0.5},
+		{name: "Inf", k: math.Inf(1)},
+		{name: "NaN", k: math.NaN()},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				if got := recover() == nil; got != c.ok {
+					t.Errorf("NewLoose() panicked = %v, want = %v", !got, !c.ok)
+				}
+			}()
+			NewLoose(bounds, c.k, 5)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package quadtree
+
+import (
+	"sort"
+
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+// SetMergeDelay configures when empty sibling leaves are merged back into
+// their parent.
+//
+// By default (a delay of 0), siblings are merged as soon as they become empty.
+// This causes churn when an object repeatedly crosses a cell boundary, as each
+// Remove collapses the parent and the next Insert splits it again. A positive
+// delay only merges siblings once they have stayed empty for the given number
+// of modifications, and a negative delay disables automatic merging entirely,
+// in which case empty siblings are only merged by Compact.
+//
+// Setting the delay to 0 compacts the tree. Otherwise, empty siblings which
+// exist at the time the delay is changed are only merged by Compact.
+func (qt *QT) SetMergeDelay(d int) {
+	qt.delay = d
+	qt.since = nil
+	if d > 0 {
+		qt.since = map[*node.N]uint64{}
+	}
+
+	qt.root.SetLazy(d != 0)
+	if d == 0 {
+		qt.Compact()
+	}
+}
+
+// Compact merges all collapsible sibling leaves in the tree, i.e. siblings
+// which are empty or fully covered by the same set of objects.
+func (qt *QT) Compact() {
+	for n := range qt.since {
+		delete(qt.since, n)
+	}
+	if qt.root.Compact(qt.aabb) > 0 {
+		qt.version++
+	}
+}
+
+// track records when a node becomes collapsible, i.e. when all of its children
+// are leaves which are empty or fully covered by the same set of objects.
+func (qt *QT) track(e node.Event, n *node.N) {
+	switch e {
+	case EventSplit:
+		if p := n.Parent(); p != nil {
+			delete(qt.since, p)
+		}
+	case EventMerge, EventOccupancy:
+		delete(qt.since, n)
+
+		p := n.Parent()
+		if p == nil {
+			return
+		}
+		if !p.Collapsible(qt.aabb) {
+			delete(qt.since, p)
+		} else if _, ok := qt.since[p]; !ok {
+			qt.since[p] = qt.version
+		}
+	}
+}
+
+// merge collapses all nodes which have stayed collapsible for at least the
+// merge delay. Nodes are collapsed in Morton order, which ensures the
+// resulting events are reported in the same order across runs.
+func (qt *QT) merge() {
+	qt.expired = qt.expired[:0]
+	for n, v := range qt.since {
+		if qt.version-v >= uint64(qt.delay) {
+			qt.expired = append(qt.expired, n)
+		}
+	}
+	if len(qt.expired) == 0 {
+		return
+	}
+	sort.Sort(&qt.expired)
+
+	for _, n := range qt.expired {
+		delete(qt.since, n)
+		// Nodes under a previously collapsed ancestor are detached
+		// from the tree.
+		if n.Root() == qt.root {
+			n.Collapse(qt.aabb)
+		}
+	}
+}
+
+// morton sorts nodes in Morton order. Sorting a pointer to a morton slice does
+// not allocate, unlike sort.Slice.
+type morton []*node.N
+
+func (m morton) Len() int           { return len(m) }
+func (m morton) Less(i, j int) bool { return node.Less(m[i].ID(), m[j].ID()) }
+func (m morton) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
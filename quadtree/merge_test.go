@@ -0,0 +1,156 @@
+package quadtree
+
+import (
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+func TestMergeDelay(t *testing.T) {
+	bounds := *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})
+	a := *hyperrectangle.New(vector.V{10, 10}, vector.V{20, 20})
+	b := *hyperrectangle.New(vector.V{60, 10}, vector.V{70, 20})
+
+	t.Run("Churn", func(t *testing.T) {
+		type config struct {
+			name  string
+			delay int
+			want  bool
+		}
+
+		configs := []config{
+			{name: "Eager", delay: 0, want: true},
+			{name: "Delayed", delay: 4, want: false},
+			{name: "Manual", delay: -1, want: false},
+		}
+
+		for _, c := range configs {
+			t.Run(c.name, func(t *testing.T) {
+				qt := New(bounds, 0, 3)
+				qt.SetMergeDelay(c.delay)
+				if err := qt.Insert(1, a); err != nil {
+					t.Fatalf("Insert() = %v, want = nil", err)
+				}
+				if err := qt.Update(1, b); err != nil {
+					t.Fatalf("Update() = %v, want = nil", err)
+				}
+
+				churn := false
+				cancel := qt.Observe(func(e Event) {
+					if e.Type == EventSplit || e.Type == EventMerge {
+						churn = true
+					}
+				})
+				defer cancel()
+
+				for i := 0; i < 10; i++ {
+					aabb := a
+					if i%2 == 1 {
+						aabb = b
+					}
+					if err := qt.Update(1, aabb); err != nil {
+						t.Fatalf("Update() = %v, want = nil", err)
+					}
+				}
+				if churn != c.want {
+					t.Errorf("churn = %v, want = %v", churn, c.want)
+				}
+			})
+		}
+	})
+
+	t.Run("Allocs", func(t *testing.T) {
+		qt := New(bounds, 0, 3)
+		qt.SetMergeDelay(10)
+		if err := qt.Insert(1, a); err != nil {
+			t.Fatalf("Insert() = %v, want = nil", err)
+		}
+		if err := qt.Update(1, b); err != nil {
+			t.Fatalf("Update() = %v, want = nil", err)
+		}
+
+		i := 0
+		if got := testing.AllocsPerRun(100, func() {
+			aabb := a
+			if i%2 == 1 {
+				aabb = b
+			}
+			i++
+			if err := qt.Update(1, aabb); err != nil {
+				t.Fatalf("Update() = %v, want = nil", err)
+			}
+		}); got != 0 {
+			t.Errorf("AllocsPerRun() = %v, want = 0", got)
+		}
+	})
+
+	t.Run("Delayed", func(t *testing.T) {
+		const delay = 3
+
+		qt := New(bounds, 0, 3)
+		qt.SetMergeDelay(delay)
+
+		c := *hyperrectangle.New(vector.V{90, 90}, vector.V{95, 95})
+		d := *hyperrectangle.New(vector.V{91, 91}, vector.V{96, 96})
+		for x, aabb := range []hyperrectangle.R{c, a} {
+			if err := qt.Insert(id.ID(x), aabb); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+		}
+		if err := qt.Remove(1); err != nil {
+			t.Fatalf("Remove() = %v, want = nil", err)
+		}
+
+		sw := qt.root.Children()[node.ChildSW]
+		for i := 0; i < delay; i++ {
+			if sw.IsLeaf() {
+				t.Fatalf("IsLeaf() = true, want = false")
+			}
+			aabb := d
+			if i%2 == 1 {
+				aabb = c
+			}
+			if err := qt.Update(0, aabb); err != nil {
+				t.Fatalf("Update() = %v, want = nil", err)
+			}
+		}
+		if !sw.IsLeaf() {
+			t.Errorf("IsLeaf() = false, want = true")
+		}
+	})
+
+	t.Run("Compact", func(t *testing.T) {
+		eager, manual := New(bounds, 0, 3), New(bounds, 0, 3)
+		manual.SetMergeDelay(-1)
+		for _, qt := range []*QT{eager, manual} {
+			for _, aabb := range []hyperrectangle.R{a, b} {
+				if err := qt.Insert(1, aabb); err != nil {
+					t.Fatalf("Insert() = %v, want = nil", err)
+				}
+				if err := qt.Remove(1); err != nil {
+					t.Fatalf("Remove() = %v, want = nil", err)
+				}
+			}
+		}
+
+		// The checksum covers the merge delay, which must match in
+		// order to compare the tree structures. Changing a non-zero
+		// delay does not compact the tree.
+		eager.SetMergeDelay(-1)
+
+		if manual.Checksum() == eager.Checksum() {
+			t.Fatalf("Checksum() = %v, want != %v", manual.Checksum(), eager.Checksum())
+		}
+		v := manual.version
+		manual.Compact()
+		if got, want := manual.Checksum(), eager.Checksum(); got != want {
+			t.Errorf("Checksum() = %v, want = %v", got, want)
+		}
+		if manual.version == v {
+			t.Errorf("version = %v, want != %v", manual.version, v)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package quadtree
+
+import (
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+type EventType = node.Event
+
+const (
+	EventSplit     = node.EventSplit
+	EventMerge     = node.EventMerge
+	EventOccupancy = node.EventOccupancy
+)
+
+// Event describes a structural change to a single cell in the tree.
+//
+// On EventSplit, the cell has become an internal node, and its former contents
+// have been distributed to its four new children; on EventMerge, the children
+// of the cell have been discarded and the cell is a leaf again. On
+// EventOccupancy, the set of objects overlapping the leaf cell has changed.
+type Event struct {
+	Type EventType
+	ID   string
+
+	// AABB is a copy of the cell bounds, which may be safely modified.
+	AABB hyperrectangle.R
+}
+
+// Observer is called synchronously from within Insert and Remove. Observers
+// must not mutate the tree.
+type Observer func(e Event)
+
+type observer struct {
+	handle int
+	f      Observer
+}
+
+// Observe registers a callback which fires whenever a cell is split, merged, or
+// changes occupancy. Observers are called in the order in which they were
+// registered. Observe returns a function which unregisters the callback.
+func (qt *QT) Observe(f Observer) func() {
+	qt.handle++
+	h := qt.handle
+	qt.observers = append(qt.observers, observer{handle: h, f: f})
+
+	return func() {
+		for i, o := range qt.observers {
+			if o.handle == h {
+				qt.observers = append(qt.observers[:i], qt.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (qt *QT) notify(e node.Event, n *node.N) {
+	if qt.since != nil {
+		qt.track(e, n)
+	}
+	if len(qt.observers) == 0 {
+		return
+	}
+	ev := Event{
+		Type: e,
+		ID:   n.ID(),
+		AABB: clone(n.AABB()),
+	}
+	for _, o := range qt.observers {
+		o.f(ev)
+	}
+}
@@ -0,0 +1,60 @@
+package quadtree
+
+import (
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestObserve(t *testing.T) {
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 1)
+
+	var got []Event
+	cancel := qt.Observe(func(e Event) { got = append(got, e) })
+
+	if err := qt.Insert(1, *hyperrectangle.New(vector.V{1, 1}, vector.V{2, 2})); err != nil {
+		t.Fatalf("Insert() = %v, want = nil", err)
+	}
+	if err := qt.Remove(1); err != nil {
+		t.Fatalf("Remove() = %v, want = nil", err)
+	}
+
+	want := []Event{
+		{Type: EventSplit, ID: "", AABB: *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})},
+		{Type: EventOccupancy, ID: "2", AABB: *hyperrectangle.New(vector.V{0, 0}, vector.V{50, 50})},
+		{Type: EventOccupancy, ID: "2", AABB: *hyperrectangle.New(vector.V{0, 0}, vector.V{50, 50})},
+		{Type: EventMerge, ID: "", AABB: *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.EquateEmpty(), cmp.AllowUnexported(hyperrectangle.R{})); diff != "" {
+		t.Errorf("Observe() mismatch (-want +got):\n%v", diff)
+	}
+
+	cancel()
+	got = nil
+	if err := qt.Insert(1, *hyperrectangle.New(vector.V{1, 1}, vector.V{2, 2})); err != nil {
+		t.Fatalf("Insert() = %v, want = nil", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Observe() fired %v events after cancellation, want = 0", len(got))
+	}
+}
+
+func TestObserveAABB(t *testing.T) {
+	bounds := *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})
+	qt := New(bounds, 0, 1)
+
+	cancel := qt.Observe(func(e Event) { e.AABB.M().Min().SetX(vector.AXIS_X, -50) })
+	defer cancel()
+	if err := qt.Insert(1, *hyperrectangle.New(vector.V{10, 10}, vector.V{20, 20})); err != nil {
+		t.Fatalf("Insert() = %v, want = nil", err)
+	}
+
+	for _, n := range qt.root.Leaves(bounds) {
+		if got := n.AABB().Min().X(vector.AXIS_X); got < 0 {
+			t.Errorf("Min() = %v, want >= 0", got)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package quadtree
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+)
+
+// DefaultFloor is the depth floor of trees created by NewWithOptions, unless
+// overridden by WithFloor.
+const DefaultFloor = 16
+
+// Option configures a tree created by NewWithOptions.
+type Option func(o *options)
+
+type options struct {
+	floor  int
+	policy SplitPolicy
+	delay  int
+	expand bool
+	bounds BoundsPolicy
+
+	// loose is the loose factor, which is only used if isLoose is set.
+	loose   float64
+	isLoose bool
+}
+
+// WithFloor sets the maximum depth of the tree, i.e. leaves at this depth are
+// never split. The floor must be positive.
+func WithFloor(f int) Option {
+	return func(o *options) { o.floor = f }
+}
+
+// WithTolerance splits leaves according to the Tolerance policy. This is the
+// default, with a tolerance of 0.
+func WithTolerance(t float64) Option {
+	return func(o *options) { o.policy = Tolerance(t) }
+}
+
+// WithSplitPolicy sets the policy which decides when leaves are split.
+func WithSplitPolicy(p SplitPolicy) Option {
+	return func(o *options) { o.policy = p }
+}
+
+// WithLoose stores objects in a loose quadtree with the input loose factor,
+// which must be at least 1. See NewLoose.
+func WithLoose(k float64) Option {
+	return func(o *options) {
+		o.loose = k
+		o.isLoose = true
+	}
+}
+
+// WithMergeDelay sets the number of modifications for which sibling leaves
+// must stay mergeable before being merged. See SetMergeDelay.
+func WithMergeDelay(d int) Option {
+	return func(o *options) { o.delay = d }
+}
+
+// WithExpand grows the tree to fit objects inserted outside of its bounds. See
+// SetExpand.
+func WithExpand() Option {
+	return func(o *options) { o.expand = true }
+}
+
+// WithBoundsPolicy sets how objects which extend past the bounds of the tree
+// are handled. See SetBoundsPolicy.
+func WithBoundsPolicy(p BoundsPolicy) Option {
+	return func(o *options) { o.bounds = p }
+}
+
+// NewWithOptions returns a tree over the input bounds, configured by the
+// input options. NewWithOptions validates the configuration, and returns an
+// error instead of panicking if it is invalid.
+func NewWithOptions(bounds hyperrectangle.R, opts ...Option) (*QT, error) {
+	o := options{
+		floor: DefaultFloor,
+	}
+	for _, f := range opts {
+		f(&o)
+	}
+
+	if bounds.Min().Dimension() != 2 || bounds.Max().Dimension() != 2 {
+		return nil, fmt.Errorf("invalid bounds %v: must be two-dimensional", bounds)
+	}
+	for _, i := range []vector.D{vector.AXIS_X, vector.AXIS_Y} {
+		min, max := bounds.Min().X(i), bounds.Max().X(i)
+		for _, x := range []float64{min, max} {
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				return nil, fmt.Errorf("invalid bounds %v: coordinates must be finite", bounds)
+			}
+		}
+		if !(min < max) {
+			return nil, fmt.Errorf("invalid bounds %v: must have a positive area", bounds)
+		}
+	}
+
+	if o.floor <= 0 {
+		return nil, fmt.Errorf("invalid floor %v: must be positive", o.floor)
+	}
+	if t, ok := o.policy.(Tolerance); ok && !(t >= 0) {
+		return nil, fmt.Errorf("invalid tolerance %v: must be non-negative", float64(t))
+	}
+	if c, ok := o.policy.(Capacity); ok && c <= 0 {
+		return nil, fmt.Errorf("invalid capacity %v: must be positive", int(c))
+	}
+	switch o.bounds {
+	case BoundsOverlap, BoundsContain, BoundsClip:
+	default:
+		return nil, fmt.Errorf("invalid bounds policy %v", o.bounds)
+	}
+
+	if o.isLoose {
+		if err := checkLoose(o.loose); err != nil {
+			return nil, err
+		}
+		if o.policy != nil {
+			return nil, fmt.Errorf("loose trees do not support split policies")
+		}
+		if o.expand {
+			return nil, fmt.Errorf("loose trees do not support expansion")
+		}
+		if o.delay != 0 {
+			return nil, fmt.Errorf("loose trees do not support merge delays")
+		}
+	}
+
+	var qt *QT
+	switch {
+	case o.isLoose:
+		qt = NewLoose(bounds, o.loose, o.floor)
+	case o.policy != nil:
+		qt = NewWithPolicy(bounds, o.policy, o.floor)
+	default:
+		qt = New(bounds, 0, o.floor)
+	}
+	if o.delay != 0 {
+		qt.SetMergeDelay(o.delay)
+	}
+	qt.SetExpand(o.expand)
+	qt.SetBoundsPolicy(o.bounds)
+
+	return qt, nil
+}
@@ -0,0 +1,98 @@
+package quadtree
+
+import (
+	"math"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	bounds := *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})
+
+	type config struct {
+		name   string
+		bounds hyperrectangle.R
+		opts   []Option
+		ok     bool
+	}
+
+	configs := []config{
+		{name: "Default", bounds: bounds, ok: true},
+		{name: "Bounds/Empty", bounds: hyperrectangle.R{}},
+		{name: "Bounds/ZeroArea", bounds: *hyperrectangle.New(vector.V{0, 0}, vector.V{0, 100})},
+		{name: "Bounds/NaN", bounds: *hyperrectangle.New(vector.V{math.NaN(), 0}, vector.V{100, 100})},
+		{name: "Bounds/Inf", bounds: *hyperrectangle.New(vector.V{0, 0}, vector.V{math.Inf(1), 100})},
+		{name: "Floor", bounds: bounds, opts: []Option{WithFloor(3)}, ok: true},
+		{name: "Floor/Zero", bounds: bounds, opts: []Option{WithFloor(0)}},
+		{name: "Tolerance", bounds: bounds, opts: []Option{WithTolerance(1)}, ok: true},
+		{name: "Tolerance/Negative", bounds: bounds, opts: []Option{WithTolerance(-1)}},
+		{name: "Tolerance/NaN", bounds: bounds, opts: []Option{WithTolerance(math.NaN())}},
+		{name: "Capacity", bounds: bounds, opts: []Option{WithSplitPolicy(Capacity(4))}, ok: true},
+		{name: "Capacity/Zero", bounds: bounds, opts: []Option{WithSplitPolicy(Capacity(0))}},
+		{name: "Loose", bounds: bounds, opts: []Option{WithLoose(2)}, ok: true},
+		{name: "Loose/Tight", bounds: bounds, opts: []Option{WithLoose(0.5)}},
+		{name: "Loose/Zero", bounds: bounds, opts: []Option{WithLoose(0)}},
+		{name: "Loose/Negative", bounds: bounds, opts: []Option{WithLoose(-1)}},
+		{name: "Loose/Inf", bounds: bounds, opts: []Option{WithLoose(math.Inf(1))}},
+		{name: "Loose/Policy", bounds: bounds, opts: []Option{WithLoose(2), WithTolerance(1)}},
+		{name: "Loose/Expand", bounds: bounds, opts: []Option{WithLoose(2), WithExpand()}},
+		{name: "Loose/MergeDelay", bounds: bounds, opts: []Option{WithLoose(2), WithMergeDelay(1)}},
+		{name: "MergeDelay", bounds: bounds, opts: []Option{WithMergeDelay(4)}, ok: true},
+		{name: "Expand", bounds: bounds, opts: []Option{WithExpand()}, ok: true},
+		{name: "BoundsPolicy", bounds: bounds, opts: []Option{WithBoundsPolicy(BoundsClip)}, ok: true},
+		{name: "BoundsPolicy/Invalid", bounds: bounds, opts: []Option{WithBoundsPolicy(BoundsPolicy(100))}},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			qt, err := NewWithOptions(c.bounds, c.opts...)
+			if ok := err == nil; ok != c.ok {
+				t.Fatalf("NewWithOptions() = %v, want ok = %v", err, c.ok)
+			}
+			if c.ok {
+				if err := qt.Validate(); err != nil {
+					t.Errorf("Validate() = %v, want = nil", err)
+				}
+			}
+		})
+	}
+
+	t.Run("Modes", func(t *testing.T) {
+		qt, err := NewWithOptions(
+			bounds,
+			WithFloor(3),
+			WithSplitPolicy(Capacity(4)),
+			WithMergeDelay(4),
+			WithExpand(),
+			WithBoundsPolicy(BoundsContain),
+		)
+		if err != nil {
+			t.Fatalf("NewWithOptions() = %v, want = nil", err)
+		}
+		if got, want := qt.root.Floor(), 3; got != want {
+			t.Errorf("Floor() = %v, want = %v", got, want)
+		}
+		if got, want := qt.policy, SplitPolicy(Capacity(4)); got != want {
+			t.Errorf("policy = %v, want = %v", got, want)
+		}
+		if got, want := qt.delay, 4; got != want {
+			t.Errorf("delay = %v, want = %v", got, want)
+		}
+		if !qt.expand {
+			t.Errorf("expand = false, want = true")
+		}
+		if got, want := qt.bounds, BoundsContain; got != want {
+			t.Errorf("bounds = %v, want = %v", got, want)
+		}
+
+		l, err := NewWithOptions(bounds, WithLoose(2))
+		if err != nil {
+			t.Fatalf("NewWithOptions() = %v, want = nil", err)
+		}
+		if l.loose == nil {
+			t.Errorf("loose = nil, want != nil")
+		}
+	})
+}
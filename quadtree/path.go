@@ -0,0 +1,461 @@
+package quadtree
+
+import (
+	"container/heap"
+	"context"
+	"math"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+// PathOption configures the behavior of Path.
+type PathOption func(o *pathOptions)
+
+type pathOptions struct {
+	mask      Layer
+	nearest   bool
+	closed    bool
+	heuristic Heuristic
+	edge      EdgeCost
+}
+
+// WithMask restricts the objects considered by Path to those on the input
+// collision layers. Objects on other layers are treated as empty space. By
+// default, Path considers objects on all layers.
+func WithMask(m Layer) PathOption {
+	return func(o *pathOptions) { o.mask = m }
+}
+
+// WithNearest allows Path to substitute the goal with the closest reachable
+// point if the goal itself cannot be reached, e.g. if the goal lies inside an
+// obstacle, outside the tree, or in a region disconnected from the source.
+//
+// Finding the substitute requires expanding every leaf reachable from the
+// source. If the tree already tracks connectivity for the search mask, i.e.
+// ComponentID or Connected has been called with the same mask, the search
+// instead reuses the component labels, unless the search uses a custom edge
+// cost.
+func WithNearest() PathOption {
+	return func(o *pathOptions) { o.nearest = true }
+}
+
+// WithClosed records the IDs of all leaf cells expanded by the search in the
+// Result. This is useful for debugging, but is expensive for long paths.
+func WithClosed() PathOption {
+	return func(o *pathOptions) { o.closed = true }
+}
+
+// Result is the output of a path search.
+type Result struct {
+	// Path is the list of waypoints from the source to Goal, or nil if
+	// no path exists.
+	Path []vector.V
+
+	// Goal is the final waypoint of the path.
+	Goal vector.V
+
+	// Substituted indicates that the requested goal was unreachable, and
+	// has been replaced by the closest reachable point. See WithNearest.
+	Substituted bool
+
+	// Cost is the total cost of the path, i.e. the length of each segment
+	// of Path, scaled by the cost multiplier of the leaf containing the
+	// segment, or +Inf if no path exists. If a custom edge cost is set,
+	// Cost is instead the sum of the edge costs between consecutive cells.
+	//
+	// The search minimizes the cost of moving between leaf centers via
+	// the portal midpoints, while Path only visits the portal midpoints,
+	// i.e. Cost may be lower than the cost minimized by the search.
+	Cost float64
+
+	// Cells is the sequence of IDs of the leaf cells traversed by the
+	// path.
+	Cells []string
+
+	// Expanded is the total number of leaf cells expanded by the search.
+	Expanded int
+
+	// Closed lists the IDs of the expanded leaf cells in expansion order.
+	// Closed is only populated if the WithClosed option is set.
+	Closed []string
+}
+
+func newPathOptions(opts []PathOption) pathOptions {
+	o := pathOptions{
+		mask: LayerAll,
+	}
+	for _, f := range opts {
+		f(&o)
+	}
+	return o
+}
+
+// graph is the search graph over the leaves of the tree for a single source
+// and goal point.
+type graph struct {
+	qt        *QT
+	mask      Layer
+	heuristic Heuristic
+	edge      EdgeCost
+
+	s vector.V
+	g vector.V
+
+	src *node.N
+	dst *node.N
+
+	// h scales the Euclidean heuristic by the cheapest cost multiplier in
+	// the tree, which ensures the heuristic remains admissible when
+	// objects cheaper than empty space (e.g. roads) exist.
+	h float64
+
+	// cache memoizes the neighbors of each leaf for the lifetime of the
+	// graph, i.e. while the tree is not modified.
+	cache   map[*node.N][]*node.N
+	weights map[*node.N]float64
+}
+
+// graph returns the search graph between s and g. The source and goal points
+// may be nil, in which case all leaves are represented by their centers.
+func (qt *QT) graph(s vector.V, g vector.V, o pathOptions) graph {
+	h := 1.0
+	for _, v := range qt.objects {
+		if v.layer&o.mask != 0 {
+			h = math.Min(h, v.cost)
+		}
+	}
+	// The default heuristic assumes the default edge cost, and may
+	// overestimate custom edge costs.
+	heuristic := o.heuristic
+	if heuristic == nil && o.edge != nil {
+		heuristic = Zero
+	}
+	gr := graph{
+		qt:        qt,
+		mask:      o.mask,
+		heuristic: heuristic,
+		edge:      o.edge,
+		s:         s,
+		g:         g,
+		h:         h,
+
+		cache:   make(map[*node.N][]*node.N, 64),
+		weights: make(map[*node.N]float64, 64),
+	}
+	if s != nil {
+		gr.src = qt.root.Leaf(s)
+	}
+	if g != nil {
+		gr.dst = qt.root.Leaf(g)
+	}
+	return gr
+}
+
+// neighbors returns the leaves adjacent to the input leaf.
+func (gr graph) neighbors(n *node.N) []*node.N {
+	if ns, ok := gr.cache[n]; ok {
+		return ns
+	}
+	ns := n.Neighbors()
+	gr.cache[n] = ns
+	return ns
+}
+
+// position returns the representative point of a leaf in the search graph.
+func (gr graph) position(n *node.N) vector.V {
+	switch n {
+	case gr.src:
+		return gr.s
+	case gr.dst:
+		return gr.g
+	default:
+		return center(n.AABB())
+	}
+}
+
+// estimate returns a lower bound on the cost of moving between the input
+// leaves. estimate returns 0 if either leaf is nil.
+func (gr graph) estimate(n *node.N, m *node.N) float64 {
+	if n == nil || m == nil {
+		return 0
+	}
+	if gr.heuristic != nil {
+		return gr.heuristic.Heuristic(gr.position(n), gr.position(m))
+	}
+	return float64(gr.h * distance(gr.position(n), gr.position(m)))
+}
+
+// weight returns the traversal cost multiplier of the input leaf.
+func (gr graph) weight(n *node.N) float64 {
+	if w, ok := gr.weights[n]; ok {
+		return w
+	}
+	w := gr.qt.weight(n, gr.mask)
+	gr.weights[n] = w
+	return w
+}
+
+// blocked checks if the input leaf is impassable.
+func (gr graph) blocked(n *node.N) bool { return math.IsInf(gr.weight(n), 1) }
+
+// cost returns the cost of moving from the representative point of n to the
+// representative point of the adjacent leaf m, via the midpoint of the shared
+// portal. cost returns false if the move is not allowed, e.g. if m is blocked,
+// or if the leaves only share a corner.
+func (gr graph) cost(n *node.N, m *node.N) (vector.V, float64, bool) {
+	w, v := gr.weight(n), gr.weight(m)
+	if math.IsInf(w, 1) || math.IsInf(v, 1) {
+		return nil, 0, false
+	}
+	r, ok := node.Portal(n, m)
+	if !ok || vector.Within(r.Min(), r.Max()) {
+		return nil, 0, false
+	}
+
+	portal := center(r)
+	if gr.edge != nil {
+		c := gr.edge.Cost(&Cell{n: n}, &Cell{n: m})
+		// Negative costs would break the closed set of A* and may
+		// create negative cycles, and are treated as forbidden moves.
+		if math.IsNaN(c) || math.IsInf(c, 1) || c < 0 {
+			return nil, 0, false
+		}
+		return portal, c, true
+	}
+	// Products are explicitly rounded, which prevents the compiler from
+	// fusing operations, e.g. into FMA instructions on arm64, and keeps
+	// the cost identical across platforms.
+	return portal, float64(w*distance(portal, gr.position(n))) + float64(v*distance(gr.position(m), portal)), true
+}
+
+// Path returns a list of waypoints from s to g which avoids all impassable
+// objects in the tree. If no such path exists, the returned Result has a nil
+// Path.
+//
+// Path runs A* over the leaves of the tree. Moving through a leaf is priced
+// as the distance travelled within the leaf, scaled by the highest cost
+// multiplier of any object overlapping the leaf. Waypoints pass through the
+// midpoints of the portals shared between consecutive leaves. Leaves which
+// only touch at a corner are not considered connected, i.e. paths will not
+// squeeze diagonally between two obstacles.
+func (qt *QT) Path(s vector.V, g vector.V, opts ...PathOption) Result {
+	r := qt.request(context.Background(), s, g, newPathOptions(opts))
+	r.Step(0)
+	return r.result
+}
+
+// search is a resumable A* search from src over all leaves accepted by the
+// within filter, which terminates when dst is reached. A nil dst expands every
+// reachable leaf, i.e. runs Dijkstra's algorithm, and a nil filter accepts
+// every leaf.
+type search struct {
+	gr     graph
+	src    *node.N
+	dst    *node.N
+	within func(n *node.N) bool
+
+	// costs and parents track the cost of and the parent on the shortest
+	// known path to each visited leaf. The source leaf is its own parent.
+	costs   map[*node.N]float64
+	parents map[*node.N]*node.N
+	closed  map[*node.N]bool
+	open    *frontier
+
+	// order lists the closed leaves in expansion order, if requested.
+	order []*node.N
+	trace bool
+}
+
+func (gr graph) newSearch(src *node.N, dst *node.N, within func(n *node.N) bool) *search {
+	s := &search{
+		gr:      gr,
+		src:     src,
+		dst:     dst,
+		within:  within,
+		costs:   map[*node.N]float64{},
+		parents: map[*node.N]*node.N{},
+		closed:  map[*node.N]bool{},
+		open:    &frontier{},
+	}
+	s.seed(src)
+	return s
+}
+
+// seed adds an additional source leaf to the search.
+func (s *search) seed(n *node.N) {
+	if _, ok := s.costs[n]; ok {
+		return
+	}
+	s.costs[n] = 0
+	s.parents[n] = n
+	heap.Push(s.open, entry{n: n, p: s.gr.estimate(n, s.dst)})
+}
+
+// reached checks if the search has found the shortest path to dst.
+func (s *search) reached() bool { return s.dst != nil && s.closed[s.dst] }
+
+// step expands up to k leaves, and returns the number of leaves expanded and
+// whether or not the search has terminated. A non-positive k runs the search
+// to completion.
+func (s *search) step(k int) (int, bool) {
+	i := 0
+	for k <= 0 || i < k {
+		if s.open.Len() == 0 || s.reached() {
+			return i, true
+		}
+
+		n := heap.Pop(s.open).(entry).n
+		if s.closed[n] {
+			continue
+		}
+		s.closed[n] = true
+		if s.trace {
+			s.order = append(s.order, n)
+		}
+		i++
+
+		if n == s.dst {
+			return i, true
+		}
+
+		for _, m := range s.gr.neighbors(n) {
+			if s.closed[m] || s.within != nil && !s.within(m) {
+				continue
+			}
+			_, c, ok := s.gr.cost(n, m)
+			if !ok {
+				continue
+			}
+			c += s.costs[n]
+			if d, ok := s.costs[m]; ok && d <= c {
+				continue
+			}
+
+			s.costs[m] = c
+			s.parents[m] = n
+			heap.Push(s.open, entry{n: m, p: c + s.gr.estimate(m, s.dst)})
+		}
+	}
+	return i, s.open.Len() == 0 || s.reached()
+}
+
+type entry struct {
+	n *node.N
+	p float64
+}
+
+// frontier is a lazily updated min-heap of leaves. Leaves with the same
+// priority are ordered by their Morton order, which ensures searches expand
+// leaves in the same order across runs.
+type frontier []entry
+
+func (f frontier) Len() int { return len(f) }
+func (f frontier) Less(i, j int) bool {
+	return f[i].p < f[j].p || f[i].p == f[j].p && node.Less(f[i].n.ID(), f[j].n.ID())
+}
+func (f frontier) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
+func (f *frontier) Push(x any)   { *f = append(*f, x.(entry)) }
+func (f *frontier) Pop() any {
+	old := *f
+	e := old[len(old)-1]
+	*f = old[:len(old)-1]
+	return e
+}
+
+// search runs a search to completion, and returns the cost of and the parent
+// on the shortest path to each closed leaf.
+func (gr graph) search(src *node.N, dst *node.N, within func(n *node.N) bool) (map[*node.N]float64, map[*node.N]*node.N) {
+	s := gr.newSearch(src, dst, within)
+	s.step(0)
+
+	for n := range s.costs {
+		if !s.closed[n] {
+			delete(s.costs, n)
+			delete(s.parents, n)
+		}
+	}
+	return s.costs, s.parents
+}
+
+// trace returns the chain of leaves from the search source to the input leaf.
+func trace(parents map[*node.N]*node.N, n *node.N) []*node.N {
+	chain := []*node.N{n}
+	for parents[n] != n {
+		n = parents[n]
+		chain = append(chain, n)
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+// waypoints converts a chain of adjacent leaves from the source leaf to the
+// goal leaf into a list of waypoints through the midpoints of the shared
+// portals.
+func (gr graph) waypoints(chain []*node.N) []vector.V {
+	path := make([]vector.V, 0, len(chain)+1)
+	path = append(path, gr.s)
+	for i := 1; i < len(chain); i++ {
+		r, _ := node.Portal(chain[i-1], chain[i])
+		path = append(path, center(r))
+	}
+	return append(path, gr.g)
+}
+
+// price returns the cost of the input waypoints, as returned by waypoints for
+// the input chain of leaves. Each segment between consecutive waypoints lies
+// in the corresponding leaf, and is priced by its length, scaled by the cost
+// multiplier of the leaf.
+func (gr graph) price(chain []*node.N, path []vector.V) float64 {
+	c := 0.0
+	for i, n := range chain {
+		// See cost for the explicit rounding.
+		c += float64(gr.weight(n) * distance(path[i], path[i+1]))
+	}
+	return c
+}
+
+// weight returns the traversal cost multiplier of the input leaf, i.e. the
+// highest cost of any object on the input layers overlapping the leaf. Empty
+// leaves have a multiplier of 1.
+func (qt *QT) weight(n *node.N, mask Layer) float64 {
+	w := 0.0
+	for x := range n.Lookup() {
+		if o := qt.objects[x]; o.layer&mask != 0 {
+			w = math.Max(w, o.cost)
+		}
+	}
+	if w == 0 {
+		return 1
+	}
+	return w
+}
+
+// clamp returns the point within the AABB defined by min and max closest to
+// the input point.
+func clamp(min vector.V, max vector.V, v vector.V) vector.V {
+	return vector.V{
+		math.Min(math.Max(v.X(vector.AXIS_X), min.X(vector.AXIS_X)), max.X(vector.AXIS_X)),
+		math.Min(math.Max(v.X(vector.AXIS_Y), min.X(vector.AXIS_Y)), max.X(vector.AXIS_Y)),
+	}
+}
+
+// squared returns the squared Euclidean distance between the input points.
+// Unlike vector.SquaredMagnitude, each product is explicitly rounded, which
+// prevents fused multiply-add instructions from changing the result on some
+// platforms.
+func squared(a vector.V, b vector.V) float64 {
+	dx, dy := a.X(vector.AXIS_X)-b.X(vector.AXIS_X), a.X(vector.AXIS_Y)-b.X(vector.AXIS_Y)
+	return float64(dx*dx) + float64(dy*dy)
+}
+
+// distance returns the Euclidean distance between the input points. See
+// squared.
+func distance(a vector.V, b vector.V) float64 { return math.Sqrt(squared(a, b)) }
+
+func center(r hyperrectangle.R) vector.V {
+	return vector.Add(r.Min(), vector.Scale(0.5, r.D()))
+}
@@ -0,0 +1,269 @@
+package quadtree
+
+import (
+	"math"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestPath(t *testing.T) {
+	type object struct {
+		x    id.ID
+		aabb hyperrectangle.R
+		opts []InsertOption
+	}
+
+	type config struct {
+		name    string
+		objects []object
+		s       vector.V
+		g       vector.V
+		opts    []PathOption
+		want    []vector.V
+	}
+
+	configs := []config{
+		{
+			name: "Trivial",
+			s:    vector.V{10, 10},
+			g:    vector.V{90, 90},
+			want: []vector.V{{10, 10}, {90, 90}},
+		},
+		{
+			name: "OutOfBounds",
+			s:    vector.V{10, 10},
+			g:    vector.V{190, 190},
+			want: nil,
+		},
+		{
+			name: "Blocked/Goal",
+			objects: []object{
+				{x: 1, aabb: *hyperrectangle.New(vector.V{80, 80}, vector.V{100, 100})},
+			},
+			s:    vector.V{10, 10},
+			g:    vector.V{90, 90},
+			want: nil,
+		},
+		{
+			name: "Blocked/Wall",
+			objects: []object{
+				{x: 1, aabb: *hyperrectangle.New(vector.V{49, 0}, vector.V{51, 100})},
+			},
+			s:    vector.V{10, 10},
+			g:    vector.V{90, 10},
+			want: nil,
+		},
+		{
+			name: "Detour",
+			objects: []object{
+				{x: 1, aabb: *hyperrectangle.New(vector.V{1, 1}, vector.V{49, 49})},
+			},
+			s:    vector.V{25, 75},
+			g:    vector.V{75, 25},
+			want: []vector.V{{25, 75}, {50, 75}, {75, 50}, {75, 25}},
+		},
+		{
+			name: "Weighted/Avoid",
+			objects: []object{
+				{x: 1, aabb: *hyperrectangle.New(vector.V{1, 1}, vector.V{49, 49}), opts: []InsertOption{WithCost(10)}},
+			},
+			s:    vector.V{25, 75},
+			g:    vector.V{75, 25},
+			want: []vector.V{{25, 75}, {50, 75}, {75, 50}, {75, 25}},
+		},
+		{
+			name: "Weighted/Traverse",
+			objects: []object{
+				{x: 1, aabb: *hyperrectangle.New(vector.V{1, 1}, vector.V{49, 49}), opts: []InsertOption{WithCost(1.1)}},
+			},
+			s:    vector.V{10, 40},
+			g:    vector.V{60, 40},
+			want: []vector.V{{10, 40}, {50, 25}, {60, 40}},
+		},
+		{
+			name: "Layer/Ignore",
+			objects: []object{
+				{x: 1, aabb: *hyperrectangle.New(vector.V{49, 0}, vector.V{51, 100}), opts: []InsertOption{WithLayer(1 << 1)}},
+			},
+			s:    vector.V{10, 10},
+			g:    vector.V{90, 10},
+			opts: []PathOption{WithMask(1 << 0)},
+			want: []vector.V{{10, 10}, {50, 25}, {90, 10}},
+		},
+		{
+			name: "Layer/Blocked",
+			objects: []object{
+				{x: 1, aabb: *hyperrectangle.New(vector.V{49, 0}, vector.V{51, 100}), opts: []InsertOption{WithLayer(1<<0 | 1<<1)}},
+			},
+			s:    vector.V{10, 10},
+			g:    vector.V{90, 10},
+			opts: []PathOption{WithMask(1 << 0)},
+			want: nil,
+		},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 1)
+			for _, o := range c.objects {
+				if err := qt.Insert(o.x, o.aabb, o.opts...); err != nil {
+					t.Fatalf("Insert() = %v, want = nil", err)
+				}
+			}
+			got := qt.Path(c.s, c.g, c.opts...).Path
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Path() mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestInsertCost(t *testing.T) {
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 1)
+	for _, c := range []float64{0, -1, math.NaN()} {
+		if err := qt.Insert(1, *hyperrectangle.New(vector.V{0, 0}, vector.V{1, 1}), WithCost(c)); err == nil {
+			t.Errorf("Insert() = nil, want a non-nil error for cost %v", c)
+		}
+	}
+}
+
+func TestPathNearest(t *testing.T) {
+	type config struct {
+		name  string
+		floor int
+		aabb  hyperrectangle.R
+		s     vector.V
+		g     vector.V
+		want  Result
+	}
+
+	configs := []config{
+		{
+			name: "Reachable",
+			aabb: *hyperrectangle.New(vector.V{60, 60}, vector.V{90, 90}),
+			s:    vector.V{10, 10},
+			g:    vector.V{40, 10},
+			want: Result{
+				Path:  []vector.V{{10, 10}, {40, 10}},
+				Goal:  vector.V{40, 10},
+				Cost:  30,
+				Cells: []string{"2"},
+			},
+		},
+		{
+			name: "Blocked",
+			aabb: *hyperrectangle.New(vector.V{51, 1}, vector.V{99, 49}),
+			s:    vector.V{10, 10},
+			g:    vector.V{60, 10},
+			want: Result{
+				Path:        []vector.V{{10, 10}, {50, 10}},
+				Goal:        vector.V{50, 10},
+				Substituted: true,
+				Cost:        40,
+				Cells:       []string{"2"},
+			},
+		},
+		{
+			name:  "Disconnected",
+			floor: 2,
+			aabb:  *hyperrectangle.New(vector.V{49, 0}, vector.V{51, 100}),
+			s:     vector.V{10, 10},
+			g:     vector.V{90, 10},
+			want: Result{
+				Path:        []vector.V{{10, 10}, {25, 10}},
+				Goal:        vector.V{25, 10},
+				Substituted: true,
+				Cost:        15,
+				Cells:       []string{"22"},
+			},
+		},
+		{
+			name: "OutOfBounds",
+			aabb: *hyperrectangle.New(vector.V{60, 60}, vector.V{90, 90}),
+			s:    vector.V{10, 10},
+			g:    vector.V{-10, 10},
+			want: Result{
+				Path:        []vector.V{{10, 10}, {0, 10}},
+				Goal:        vector.V{0, 10},
+				Substituted: true,
+				Cost:        10,
+				Cells:       []string{"2"},
+			},
+		},
+		{
+			// The squared distance to the goal overflows, i.e. all
+			// leaves are equally distant, and the first reachable
+			// leaf in Morton order is chosen.
+			name: "Overflow",
+			aabb: *hyperrectangle.New(vector.V{60, 60}, vector.V{90, 90}),
+			s:    vector.V{10, 10},
+			g:    vector.V{1e200, 10},
+			want: Result{
+				Path:        []vector.V{{10, 10}, {50, 10}},
+				Goal:        vector.V{50, 10},
+				Substituted: true,
+				Cost:        40,
+				Cells:       []string{"2"},
+			},
+		},
+		{
+			name: "Infinite",
+			aabb: *hyperrectangle.New(vector.V{60, 60}, vector.V{90, 90}),
+			s:    vector.V{10, 10},
+			g:    vector.V{math.Inf(1), 0},
+			want: Result{Cost: math.Inf(1)},
+		},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			floor := c.floor
+			if floor == 0 {
+				floor = 1
+			}
+			qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, floor)
+			if err := qt.Insert(1, c.aabb); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+			if got := qt.Path(c.s, c.g); c.want.Substituted && got.Path != nil {
+				t.Fatalf("Path() = %v, want = nil without WithNearest", got.Path)
+			}
+			got := qt.Path(c.s, c.g, WithNearest())
+			if diff := cmp.Diff(c.want, got, cmpopts.IgnoreFields(Result{}, "Expanded")); diff != "" {
+				t.Errorf("Path() mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestPathResult(t *testing.T) {
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 1)
+	if err := qt.Insert(1, *hyperrectangle.New(vector.V{1, 1}, vector.V{49, 49})); err != nil {
+		t.Fatalf("Insert() = %v, want = nil", err)
+	}
+
+	got := qt.Path(vector.V{25, 75}, vector.V{75, 25}, WithClosed())
+	want := Result{
+		Path:     []vector.V{{25, 75}, {50, 75}, {75, 50}, {75, 25}},
+		Goal:     vector.V{75, 25},
+		Cost:     50 + 25*math.Sqrt2,
+		Cells:    []string{"3", "0", "1"},
+		Expanded: 3,
+		Closed:   []string{"3", "0", "1"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Path() mismatch (-want +got):\n%v", diff)
+	}
+
+	if got := qt.Path(vector.V{25, 75}, vector.V{75, 25}); got.Closed != nil {
+		t.Errorf("Closed = %v, want = nil", got.Closed)
+	}
+	if got := qt.Path(vector.V{25, 75}, vector.V{25, 25}); !math.IsInf(got.Cost, 1) {
+		t.Errorf("Cost = %v, want = %v", got.Cost, math.Inf(1))
+	}
+}
@@ -0,0 +1,306 @@
+package quadtree
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+// Planner maintains a shortest path between a fixed source and goal point,
+// and incrementally repairs the path as objects are inserted into, removed
+// from, or moved within the tree.
+//
+// Planner implements Lifelong Planning A* (LPA*) over the leaves of the tree.
+// Search state is keyed by the cell ID, which remains stable across
+// unrelated structural changes. Cells which are split or merged have their
+// state discarded, and the surrounding cells are re-examined on the next call
+// to Path.
+//
+// See Koenig, Likhachev, and Furcy 2004 for more information.
+type Planner struct {
+	qt     *QT
+	s      vector.V
+	g      vector.V
+	o      pathOptions
+	cancel func()
+
+	// changes coalesces the tree events which occurred since the last call
+	// to Path.
+	changes *changes
+
+	init bool
+	src  string
+	dst  string
+	h    float64
+
+	// costs and rhs track the LPA* search state of each leaf. IDs with any
+	// search state are indexed by prefix, which allows the stale state
+	// under a merged cell to be found.
+	costs map[string]float64
+	rhs   map[string]float64
+	index prefixes
+	open  *queue
+
+	// expanded is the number of leaves expanded by the last call to Path.
+	expanded int
+}
+
+// Planner returns a new incremental planner from s to g. The planner
+// subscribes to changes in the tree until Close is called.
+func (qt *QT) Planner(s vector.V, g vector.V, opts ...PathOption) *Planner {
+	p := &Planner{
+		qt:      qt,
+		s:       vector.V{s.X(vector.AXIS_X), s.X(vector.AXIS_Y)},
+		g:       vector.V{g.X(vector.AXIS_X), g.X(vector.AXIS_Y)},
+		o:       newPathOptions(opts),
+		changes: newChanges(),
+		costs:   map[string]float64{},
+		rhs:     map[string]float64{},
+		index:   prefixes{},
+		open:    &queue{},
+	}
+	p.cancel = qt.Observe(p.changes.observe)
+	return p
+}
+
+// Close unsubscribes the planner from the tree. The planner may not be used
+// after Close is called.
+func (p *Planner) Close() { p.cancel() }
+
+// Path returns the current shortest path from the source to the goal, or nil
+// if no such path exists. Path reuses the search state of previous calls, and
+// only re-expands cells affected by changes to the tree since the last call.
+func (p *Planner) Path() []vector.V {
+	path, _ := p.path()
+	return path
+}
+
+func (p *Planner) path() ([]vector.V, float64) {
+	gr := p.qt.graph(p.s, p.g, p.o)
+	if gr.src == nil || gr.dst == nil {
+		return nil, math.Inf(1)
+	}
+
+	if !p.init || p.changes.rebuild || gr.src.ID() != p.src || gr.dst.ID() != p.dst || gr.h != p.h {
+		p.reset(gr)
+	} else {
+		p.repair(gr)
+	}
+	p.changes.reset()
+
+	p.search(gr)
+
+	if math.IsInf(p.cost(p.dst), 1) {
+		return nil, math.Inf(1)
+	}
+
+	path := []vector.V{p.g}
+	for n := gr.dst; n != gr.src; {
+		// Guard against cycles in the case of an inconsistent search
+		// state.
+		if len(path) > len(p.costs) {
+			return nil, math.Inf(1)
+		}
+
+		var next *node.N
+		var portal vector.V
+		min := math.Inf(1)
+		for _, m := range gr.neighbors(n) {
+			q, c, ok := gr.cost(m, n)
+			if !ok {
+				continue
+			}
+			if d := p.cost(m.ID()) + c; d < min {
+				min, next, portal = d, m, q
+			}
+		}
+		if next == nil {
+			return nil, math.Inf(1)
+		}
+		path = append(path, portal)
+		n = next
+	}
+	path = append(path, p.s)
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, p.cost(p.dst)
+}
+
+func (p *Planner) cost(x string) float64 {
+	if c, ok := p.costs[x]; ok {
+		return c
+	}
+	return math.Inf(1)
+}
+
+func (p *Planner) lookahead(x string) float64 {
+	if c, ok := p.rhs[x]; ok {
+		return c
+	}
+	return math.Inf(1)
+}
+
+// set records the input search state, where +Inf discards the state.
+func (p *Planner) set(state map[string]float64, x string, c float64) {
+	_, ok := state[x]
+	if math.IsInf(c, 1) {
+		if ok {
+			p.index.remove(x)
+			delete(state, x)
+		}
+		return
+	}
+	if !ok {
+		p.index.add(x)
+	}
+	state[x] = c
+}
+
+func (p *Planner) key(gr graph, n *node.N) key {
+	c := math.Min(p.cost(n.ID()), p.lookahead(n.ID()))
+	return key{c + gr.estimate(n, gr.dst), c}
+}
+
+func (p *Planner) reset(gr graph) {
+	p.init = true
+	p.src = gr.src.ID()
+	p.dst = gr.dst.ID()
+	p.h = gr.h
+
+	for x := range p.costs {
+		delete(p.costs, x)
+	}
+	for x := range p.rhs {
+		delete(p.rhs, x)
+	}
+	for x := range p.index {
+		delete(p.index, x)
+	}
+	*p.open = (*p.open)[:0]
+
+	p.update(gr, gr.src)
+}
+
+// repair discards the search state of all split and merged cells, and
+// re-evaluates all cells which may have been affected by the changes.
+func (p *Planner) repair(gr graph) {
+	ids := p.changes.ids()
+	for _, x := range ids {
+		if !p.changes.cells[x].structural {
+			continue
+		}
+		var stale []string
+		p.index.walk(x, func(y string) { stale = append(stale, y) })
+		for _, y := range stale {
+			p.set(p.costs, y, math.Inf(1))
+			p.set(p.rhs, y, math.Inf(1))
+		}
+	}
+
+	updated := map[string]bool{}
+	for _, x := range ids {
+		for _, n := range p.qt.root.Leaves(p.changes.cells[x].aabb) {
+			if !updated[n.ID()] {
+				updated[n.ID()] = true
+				p.update(gr, n)
+			}
+			for _, m := range gr.neighbors(n) {
+				if !updated[m.ID()] {
+					updated[m.ID()] = true
+					p.update(gr, m)
+				}
+			}
+		}
+	}
+}
+
+// update recalculates the one-step lookahead cost of the input leaf.
+func (p *Planner) update(gr graph, n *node.N) {
+	x := n.ID()
+	rhs := math.Inf(1)
+	if n == gr.src {
+		if !gr.blocked(n) {
+			rhs = 0
+		}
+	} else {
+		for _, m := range gr.neighbors(n) {
+			if _, c, ok := gr.cost(m, n); ok {
+				rhs = math.Min(rhs, p.cost(m.ID())+c)
+			}
+		}
+	}
+	p.set(p.rhs, x, rhs)
+
+	if p.cost(x) != p.lookahead(x) {
+		heap.Push(p.open, item{x: x, k: p.key(gr, n)})
+	}
+}
+
+func (p *Planner) search(gr graph) {
+	p.expanded = 0
+	for p.open.Len() > 0 {
+		dst := p.dst
+		if !(*p.open)[0].k.less(p.key(gr, gr.dst)) && p.cost(dst) == p.lookahead(dst) {
+			return
+		}
+
+		it := heap.Pop(p.open).(item)
+		n := node.Find(p.qt.root, it.x)
+		if n == nil || !n.IsLeaf() {
+			continue
+		}
+
+		x := n.ID()
+		c, rhs := p.cost(x), p.lookahead(x)
+		if c == rhs {
+			continue
+		}
+		if k := p.key(gr, n); it.k.less(k) {
+			heap.Push(p.open, item{x: x, k: k})
+			continue
+		}
+
+		p.expanded++
+		if c > rhs {
+			p.set(p.costs, x, rhs)
+		} else {
+			p.set(p.costs, x, math.Inf(1))
+			p.update(gr, n)
+		}
+		for _, m := range gr.neighbors(n) {
+			p.update(gr, m)
+		}
+	}
+}
+
+// key is the lexicographically ordered LPA* priority.
+type key [2]float64
+
+func (k key) less(l key) bool { return k[0] < l[0] || k[0] == l[0] && k[1] < l[1] }
+
+type item struct {
+	x string
+	k key
+}
+
+// queue is a lazily updated min-heap of cells; stale entries are filtered out
+// by the caller on pop. Cells with the same key are ordered by their Morton
+// order.
+type queue []item
+
+func (q queue) Len() int { return len(q) }
+func (q queue) Less(i, j int) bool {
+	return q[i].k.less(q[j].k) || q[i].k == q[j].k && node.Less(q[i].x, q[j].x)
+}
+func (q queue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x any)   { *q = append(*q, x.(item)) }
+func (q *queue) Pop() any {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
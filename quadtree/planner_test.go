@@ -0,0 +1,133 @@
+package quadtree
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/downflux/go-geometry/epsilon"
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+)
+
+func rr(r *rand.Rand, min float64, max float64) hyperrectangle.R {
+	x, y := min+r.Float64()*(max-min), min+r.Float64()*(max-min)
+	w, h := r.Float64()*(max-min)/8, r.Float64()*(max-min)/8
+	return *hyperrectangle.New(vector.V{x, y}, vector.V{math.Min(x+w, max), math.Min(y+h, max)})
+}
+
+// mutate applies a random operation to the input tree over a pool of 20
+// object IDs, i.e. inserts the chosen object if it is absent, and otherwise
+// updates or removes it with equal probability. Half of the inserted objects
+// are given a random cost multiplier. New AABBs are generated by the input
+// function, which defaults to rr(r, 0, 100) if nil.
+func mutate(r *rand.Rand, qt *QT, aabb func(r *rand.Rand) hyperrectangle.R) error {
+	if aabb == nil {
+		aabb = func(r *rand.Rand) hyperrectangle.R { return rr(r, 0, 100) }
+	}
+
+	x := id.ID(r.Intn(20))
+	if !qt.Has(x) {
+		var opts []InsertOption
+		if r.Intn(2) == 0 {
+			opts = append(opts, WithCost(1+r.Float64()*4))
+		}
+		return qt.Insert(x, aabb(r), opts...)
+	}
+	if r.Intn(2) == 0 {
+		return qt.Update(x, aabb(r))
+	}
+	return qt.Remove(x)
+}
+
+func TestPlanner(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+
+	for i := 0; i < 10; i++ {
+		qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 1, 5)
+		s, g := vector.V{1, 1}, vector.V{99, 99}
+
+		p := qt.Planner(s, g)
+
+		for j := 0; j < 30; j++ {
+			if err := mutate(r, qt, nil); err != nil {
+				t.Fatalf("mutate() = %v, want = nil", err)
+			}
+
+			// Result.Cost prices the returned waypoints, whereas the
+			// planner tracks the cost minimized by the search.
+			req := qt.request(context.Background(), s, g, newPathOptions(nil))
+			req.Step(0)
+			want, wc := req.result.Path, 0.0
+			if req.search != nil {
+				wc = req.search.costs[req.gr.dst]
+			}
+			got, gc := p.path()
+			if (want == nil) != (got == nil) {
+				t.Fatalf("[%v, %v]: Path() = %v, want = %v", i, j, got, want)
+			}
+			if want != nil && !epsilon.Relative(1e-9).Within(wc, gc) {
+				t.Fatalf("[%v, %v]: Path() cost = %v, want = %v", i, j, gc, wc)
+			}
+		}
+		p.Close()
+	}
+}
+
+func TestPlannerIncremental(t *testing.T) {
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 6)
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			x, y := float64(10*i+4), float64(10*j+4)
+			if err := qt.Insert(id.ID(10*i+j), *hyperrectangle.New(vector.V{x, y}, vector.V{x + 1, y + 1})); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+		}
+	}
+	s, g := vector.V{1, 1}, vector.V{99, 99}
+
+	p := qt.Planner(s, g)
+	defer p.Close()
+	p.Path()
+
+	type config struct {
+		name string
+		aabb hyperrectangle.R
+	}
+
+	configs := []config{
+		{name: "NearGoal", aabb: *hyperrectangle.New(vector.V{90, 95}, vector.V{91, 96})},
+		{name: "OffPath", aabb: *hyperrectangle.New(vector.V{50, 20}, vector.V{51, 21})},
+		{name: "OnPath", aabb: *hyperrectangle.New(vector.V{49, 49}, vector.V{51, 51})},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			if err := qt.Insert(1000, c.aabb); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+			defer func() {
+				if err := qt.Remove(1000); err != nil {
+					t.Fatalf("Remove() = %v, want = nil", err)
+				}
+				p.Path()
+			}()
+
+			_, got := p.path()
+			f := qt.Planner(s, g)
+			defer f.Close()
+			_, want := f.path()
+
+			if !epsilon.Relative(1e-9).Within(got, want) {
+				t.Errorf("Path() cost = %v, want = %v", got, want)
+			}
+			// A local change should only re-expand a small fraction
+			// of the leaves expanded by a fresh search.
+			if 10*p.expanded >= f.expanded {
+				t.Errorf("expanded = %v, want < %v", p.expanded, f.expanded/10)
+			}
+		})
+	}
+}
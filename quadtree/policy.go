@@ -0,0 +1,53 @@
+package quadtree
+
+import (
+	"fmt"
+
+	"github.com/downflux/go-geometry/epsilon"
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+)
+
+// SplitPolicy decides when a leaf cell is subdivided to accommodate a newly
+// inserted object. Leaves at the depth floor of the tree are never split.
+//
+// Policies which implement fmt.Stringer contribute their description to the
+// tree Checksum.
+type SplitPolicy interface {
+	// Split checks if the leaf c should be split before adding an object
+	// with the input AABB. The objects already stored in c are available
+	// via c.Objects.
+	Split(c *Cell, aabb hyperrectangle.R) bool
+}
+
+// SplitPolicyFunc adapts a plain function into a SplitPolicy.
+type SplitPolicyFunc func(c *Cell, aabb hyperrectangle.R) bool
+
+func (f SplitPolicyFunc) Split(c *Cell, aabb hyperrectangle.R) bool { return f(c, aabb) }
+
+// Tolerance splits a leaf unless its volume is within the given tolerance of
+// the volume of the inserted object. This is the default policy, and suits
+// navigation occupancy, where leaves should tightly fit obstacles.
+type Tolerance float64
+
+func (t Tolerance) Split(c *Cell, aabb hyperrectangle.R) bool {
+	return !epsilon.Absolute(float64(t)).Within(
+		hyperrectangle.V(c.n.AABB()),
+		hyperrectangle.V(aabb),
+	)
+}
+
+func (t Tolerance) String() string { return fmt.Sprintf("Tolerance(%v)", float64(t)) }
+
+// Capacity splits a leaf once it would hold more than the given number of
+// objects. This suits broad-phase queries over many small objects.
+//
+// Note that leaves are not guaranteed to be fully covered by the objects they
+// hold, and path searches over such a tree treat any occupied leaf as
+// obstructed by its objects.
+type Capacity int
+
+func (k Capacity) Split(c *Cell, aabb hyperrectangle.R) bool {
+	return len(c.n.Lookup()) >= int(k)
+}
+
+func (k Capacity) String() string { return fmt.Sprintf("Capacity(%v)", int(k)) }
@@ -0,0 +1,81 @@
+package quadtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitPolicy(t *testing.T) {
+	bounds := *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})
+
+	t.Run("Tolerance", func(t *testing.T) {
+		a, b := New(bounds, 1, 5), NewWithPolicy(bounds, Tolerance(1), 5)
+		for _, qt := range []*QT{a, b} {
+			if err := qt.Insert(1, *hyperrectangle.New(vector.V{10, 10}, vector.V{20, 20})); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+		}
+		if got, want := b.Checksum(), a.Checksum(); got != want {
+			t.Errorf("Checksum() = %v, want = %v", got, want)
+		}
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		a, b := New(bounds, 0, 5), NewWithPolicy(bounds, nil, 5)
+		for _, qt := range []*QT{a, b} {
+			if err := qt.Insert(1, *hyperrectangle.New(vector.V{10, 10}, vector.V{20, 20})); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+		}
+		if got, want := b.Checksum(), a.Checksum(); got != want {
+			t.Errorf("Checksum() = %v, want = %v", got, want)
+		}
+	})
+
+	t.Run("Never", func(t *testing.T) {
+		qt := NewWithPolicy(bounds, SplitPolicyFunc(func(c *Cell, aabb hyperrectangle.R) bool { return false }), 5)
+		for i := 0; i < 10; i++ {
+			if err := qt.Insert(id.ID(i), *hyperrectangle.New(vector.V{float64(i), 0}, vector.V{float64(i) + 1, 1})); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+		}
+		if !qt.root.IsLeaf() {
+			t.Errorf("IsLeaf() = false, want = true")
+		}
+	})
+
+	t.Run("Capacity", func(t *testing.T) {
+		const k = 4
+
+		r := rand.New(rand.NewSource(0))
+		qt := NewWithPolicy(bounds, Capacity(k), 8)
+		for i := 0; i < 100; i++ {
+			x, y := r.Float64()*99, r.Float64()*99
+			if err := qt.Insert(id.ID(i), *hyperrectangle.New(vector.V{x, y}, vector.V{x + 1, y + 1})); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+		}
+
+		for _, n := range qt.root.Leaves(bounds) {
+			if got := len(n.IDs()); got > k && n.Depth() < 8 {
+				t.Errorf("len(IDs()) = %v, want <= %v", got, k)
+			}
+		}
+
+		q := *hyperrectangle.New(vector.V{25, 25}, vector.V{75, 75})
+		var want []id.ID
+		for i := 0; i < 100; i++ {
+			if !hyperrectangle.Disjoint(q, qt.aabb[id.ID(i)]) {
+				want = append(want, id.ID(i))
+			}
+		}
+		if diff := cmp.Diff(want, qt.Query(q, LayerAll)); diff != "" {
+			t.Errorf("Query() mismatch (-want +got):\n%v", diff)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package quadtree
+
+import (
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+// Portal is the boundary shared between two adjacent cells. Cells which share
+// an edge have a portal spanning the shared line segment between Min and Max;
+// diagonal neighbors share a single corner, where Min and Max coincide.
+type Portal struct {
+	Min vector.V
+	Max vector.V
+}
+
+// Midpoint returns the center of the portal segment.
+func (p Portal) Midpoint() vector.V {
+	return vector.V{
+		p.Min.X(vector.AXIS_X) + (p.Max.X(vector.AXIS_X)-p.Min.X(vector.AXIS_X))/2,
+		p.Min.X(vector.AXIS_Y) + (p.Max.X(vector.AXIS_Y)-p.Min.X(vector.AXIS_Y))/2,
+	}
+}
+
+// Closest returns the point on the portal segment closest to the input point.
+func (p Portal) Closest(v vector.V) vector.V {
+	return clamp(p.Min, p.Max, v)
+}
+
+// Portal returns the boundary shared between c and d. Portal returns false if
+// the two cells are not adjacent.
+func (c *Cell) Portal(d *Cell) (Portal, bool) {
+	r, ok := node.Portal(c.n, d.n)
+	if !ok {
+		return Portal{}, false
+	}
+	return Portal{
+		Min: r.Min(),
+		Max: r.Max(),
+	}, true
+}
@@ -2,6 +2,8 @@ package quadtree
 
 import (
 	"fmt"
+	"math"
+	"sort"
 
 	"github.com/downflux/go-geometry/nd/hyperrectangle"
 	"github.com/downflux/go-geometry/nd/vector"
@@ -10,30 +12,152 @@ import (
 )
 
 type QT struct {
-	root *node.N
+	root   *node.N
+	policy SplitPolicy
 
-	aabb map[id.ID]hyperrectangle.R
+	// loose stores the objects in place of the cell tree if set. See
+	// NewLoose.
+	loose *loose
+
+	aabb    map[id.ID]hyperrectangle.R
+	objects map[id.ID]object
+
+	// version is incremented on every modification of the tree.
+	version uint64
+
+	observers []observer
+	handle    int
+
+	// cs tracks the connected components of each collision mask, and is
+	// lazily initialized on the first connectivity query for the mask.
+	cs map[Layer]*components
+
+	// delay is the number of modifications for which sibling leaves must
+	// stay empty before being merged, and since tracks the version at
+	// which each node became collapsible. See SetMergeDelay.
+	delay   int
+	since   map[*node.N]uint64
+	expired morton
+
+	// expand indicates the root grows to fit inserted objects. See
+	// SetExpand.
+	expand bool
+
+	bounds BoundsPolicy
+}
+
+// Layer is a bitmask of collision layers. Objects are assigned to one or more
+// layers on Insert, and queries only consider objects which share at least one
+// layer with the query mask.
+type Layer uint64
+
+const (
+	LayerNone Layer = 0
+	LayerAll  Layer = math.MaxUint64
+)
+
+type object struct {
+	cost  float64
+	layer Layer
 }
 
+// InsertOption configures the properties of an object added via Insert.
+type InsertOption func(o *object)
+
+// WithCost sets the traversal cost multiplier of the object, i.e. how
+// expensive it is for a path to move through any cell the object overlaps,
+// relative to moving through empty space (which has a multiplier of 1). An
+// infinite cost marks the object as an impassable obstacle. By default, objects
+// have an infinite cost.
+func WithCost(c float64) InsertOption {
+	return func(o *object) { o.cost = c }
+}
+
+// WithLayer sets the collision layers of the object. By default, objects are
+// present on all layers.
+func WithLayer(l Layer) InsertOption {
+	return func(o *object) { o.layer = l }
+}
+
+// New returns a tree over the input bounds which splits leaves according to
+// the Tolerance policy, down to the input depth floor.
+//
+// New does not validate its configuration, and panics if the floor is not
+// positive. See NewWithOptions for a validating constructor.
 func New(bounds hyperrectangle.R, tolerance float64, floor int) *QT {
+	return NewWithPolicy(bounds, Tolerance(tolerance), floor)
+}
+
+// NewWithPolicy returns a tree over the input bounds which splits leaves
+// according to the input policy, down to the input depth floor. A nil policy
+// is equivalent to Tolerance(0).
+func NewWithPolicy(bounds hyperrectangle.R, p SplitPolicy, floor int) *QT {
+	if p == nil {
+		p = Tolerance(0)
+	}
+
 	buf := hyperrectangle.New(vector.V{0, 0}, vector.V{0, 0}).M()
 	buf.Copy(bounds)
-	return &QT{
-		root: node.New(buf.R(), tolerance, floor),
-		aabb: make(map[id.ID]hyperrectangle.R, 128),
+
+	var root *node.N
+	if t, ok := p.(Tolerance); ok {
+		root = node.New(buf.R(), float64(t), floor)
+	} else {
+		root = node.NewWithPolicy(buf.R(), func(n *node.N, aabb hyperrectangle.R) bool {
+			return p.Split(&Cell{n: n}, aabb)
+		}, floor)
+	}
+
+	qt := &QT{
+		root:    root,
+		policy:  p,
+		aabb:    make(map[id.ID]hyperrectangle.R, 128),
+		objects: make(map[id.ID]object, 128),
 	}
+	qt.root.Observe(qt.notify)
+	return qt
 }
 
-func (qt *QT) Insert(x id.ID, aabb hyperrectangle.R) error {
+func (qt *QT) Insert(x id.ID, aabb hyperrectangle.R, opts ...InsertOption) error {
 	if _, ok := qt.aabb[x]; ok {
 		return fmt.Errorf("cannot insert duplicate key %v", x)
 	}
 
+	o := object{
+		cost:  math.Inf(1),
+		layer: LayerAll,
+	}
+	for _, f := range opts {
+		f(&o)
+	}
+	if math.IsNaN(o.cost) || o.cost <= 0 {
+		return fmt.Errorf("invalid cost multiplier %v for key %v", o.cost, x)
+	}
+
+	aabb, err := qt.check(aabb)
+	if err != nil {
+		return err
+	}
+	if qt.expand && qt.loose == nil {
+		if err := qt.grow(aabb); err != nil {
+			return err
+		}
+	}
+
 	buf := hyperrectangle.New(vector.V{0, 0}, vector.V{0, 0}).M()
 	buf.Copy(aabb)
 
 	qt.aabb[x] = buf.R()
-	qt.root.Insert(x, qt.aabb)
+	qt.objects[x] = o
+	if qt.loose != nil {
+		qt.loose.insert(x, qt.aabb[x])
+	} else {
+		qt.root.Insert(x, qt.aabb)
+	}
+	if qt.delay > 0 {
+		qt.merge()
+	}
+	qt.version++
 
 	return nil
 }
@@ -43,12 +167,120 @@ func (qt *QT) Remove(x id.ID) error {
 		return fmt.Errorf("cannot remove non-existent key %v", x)
 	}
 
-	qt.root.Remove(x, qt.aabb)
+	if qt.loose != nil {
+		qt.loose.remove(x)
+	} else {
+		qt.root.Remove(x, qt.aabb)
+	}
+	if qt.delay > 0 {
+		qt.merge()
+	}
+	qt.version++
 	delete(qt.aabb, x)
+	delete(qt.objects, x)
 
 	return nil
 }
 
-func (qt *QT) Path(s vector.V, g vector.V) []vector.V {
+// Update moves an existing object to a new AABB, preserving its cost and
+// layers.
+func (qt *QT) Update(x id.ID, aabb hyperrectangle.R) error {
+	if _, ok := qt.objects[x]; !ok {
+		return fmt.Errorf("cannot update non-existent key %v", x)
+	}
+	aabb, err := qt.check(aabb)
+	if err != nil {
+		return err
+	}
+	if qt.loose != nil {
+		buf := qt.aabb[x].M()
+		buf.Copy(aabb)
+		qt.loose.update(x, qt.aabb[x])
+		qt.version++
+		return nil
+	}
+
+	if qt.expand {
+		if err := qt.grow(aabb); err != nil {
+			return err
+		}
+	}
+
+	// The object is moved in place, which reuses the stored AABB buffer,
+	// and avoids allocating when the object moves within an already split
+	// region of the tree.
+	qt.root.Remove(x, qt.aabb)
+	if qt.delay > 0 {
+		qt.merge()
+	}
+	qt.version++
+
+	buf := qt.aabb[x].M()
+	buf.Copy(aabb)
+	qt.root.Insert(x, qt.aabb)
+	if qt.delay > 0 {
+		qt.merge()
+	}
+	qt.version++
+
 	return nil
 }
+
+// NewLoose returns a loose quadtree over the input bounds. Each object is
+// stored exactly once, in the deepest node up to the input depth floor whose
+// bounds, scaled by a factor of k about the node center, fully contain the
+// object. Larger factors allow objects to move further before changing nodes,
+// at the cost of less precise query pruning.
+//
+// Loose trees are meant for broad-phase queries over fast-moving objects.
+// Objects are not stored in the cell tree, and therefore are not visible to
+// the cell and path APIs, e.g. CellAt and Path.
+//
+// NewLoose panics if k is not finite or k < 1.
+func NewLoose(bounds hyperrectangle.R, k float64, floor int) *QT {
+	if err := checkLoose(k); err != nil {
+		panic(err.Error())
+	}
+	qt := New(bounds, 0, floor)
+	qt.loose = newLoose(qt.root.AABB(), k, floor)
+	return qt
+}
+
+// CellAt returns the leaf cell which contains the input point, or nil if the
+// point lies outside the bounds of the tree.
+func (qt *QT) CellAt(p vector.V) *Cell {
+	if n := qt.root.Leaf(p); n != nil {
+		return &Cell{n: n}
+	}
+	return nil
+}
+
+// Query returns the IDs of all objects on the input layers whose AABBs overlap
+// with the query rectangle, sorted by ID.
+func (qt *QT) Query(q hyperrectangle.R, mask Layer) []id.ID {
+	ids := make([]id.ID, 0, 16)
+	if qt.loose != nil {
+		for _, x := range qt.loose.query(q) {
+			if qt.objects[x].layer&mask != 0 && !hyperrectangle.Disjoint(q, qt.aabb[x]) {
+				ids = append(ids, x)
+			}
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		return ids
+	}
+
+	seen := make(map[id.ID]bool, 16)
+	for _, n := range qt.root.Leaves(q) {
+		for _, x := range n.IDs() {
+			if seen[x] {
+				continue
+			}
+			seen[x] = true
+			if qt.objects[x].layer&mask != 0 && !hyperrectangle.Disjoint(q, qt.aabb[x]) {
+				ids = append(ids, x)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,126 @@
+package quadtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQuery(t *testing.T) {
+	qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 3)
+	for x, o := range map[id.ID]struct {
+		aabb  hyperrectangle.R
+		layer Layer
+	}{
+		1: {aabb: *hyperrectangle.New(vector.V{0, 0}, vector.V{10, 10}), layer: 1 << 0},
+		2: {aabb: *hyperrectangle.New(vector.V{5, 5}, vector.V{20, 20}), layer: 1 << 1},
+		3: {aabb: *hyperrectangle.New(vector.V{80, 80}, vector.V{90, 90}), layer: 1<<0 | 1<<1},
+	} {
+		if err := qt.Insert(x, o.aabb, WithLayer(o.layer)); err != nil {
+			t.Fatalf("Insert() = %v, want = nil", err)
+		}
+	}
+
+	type config struct {
+		name string
+		q    hyperrectangle.R
+		mask Layer
+		want []id.ID
+	}
+
+	configs := []config{
+		{
+			name: "All",
+			q:    *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}),
+			mask: LayerAll,
+			want: []id.ID{1, 2, 3},
+		},
+		{
+			name: "None",
+			q:    *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}),
+			mask: LayerNone,
+			want: []id.ID{},
+		},
+		{
+			name: "Mask",
+			q:    *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}),
+			mask: 1 << 1,
+			want: []id.ID{2, 3},
+		},
+		{
+			name: "Partial",
+			q:    *hyperrectangle.New(vector.V{12, 12}, vector.V{15, 15}),
+			mask: LayerAll,
+			want: []id.ID{2},
+		},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			if diff := cmp.Diff(c.want, qt.Query(c.q, c.mask)); diff != "" {
+				t.Errorf("Query() mismatch (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+// TestDeterminism checks that queries and path searches return identical
+// results regardless of the order in which objects were inserted.
+func TestDeterminism(t *testing.T) {
+	type output struct {
+		Query      []id.ID
+		Path       Result
+		Planner    []vector.V
+		Hierarchy  []vector.V
+		Components []int
+	}
+
+	r := rand.New(rand.NewSource(0))
+	aabbs := make([]hyperrectangle.R, 30)
+	for i := range aabbs {
+		aabbs[i] = rr(r, 0, 100)
+	}
+	s, g := vector.V{1, 1}, vector.V{99, 99}
+
+	run := func(seed int64) output {
+		qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 1, 5)
+		for _, i := range rand.New(rand.NewSource(seed)).Perm(len(aabbs)) {
+			if err := qt.Insert(id.ID(i), aabbs[i], WithCost(float64(1+i%3))); err != nil {
+				t.Fatalf("Insert() = %v, want = nil", err)
+			}
+		}
+
+		h, err := qt.Hierarchy(2)
+		if err != nil {
+			t.Fatalf("Hierarchy() = %v, want = nil", err)
+		}
+		defer h.Close()
+		p := qt.Planner(s, g)
+		defer p.Close()
+
+		var cs []int
+		for x := 0.5; x < 100; x += 10 {
+			c, _ := qt.ComponentID(vector.V{x, 100 - x}, LayerAll)
+			cs = append(cs, c)
+		}
+
+		return output{
+			Query:      qt.Query(*hyperrectangle.New(vector.V{25, 25}, vector.V{75, 75}), LayerAll),
+			Path:       qt.Path(s, g, WithClosed()),
+			Planner:    p.Path(),
+			Hierarchy:  h.Path(s, g),
+			Components: cs,
+		}
+	}
+
+	want := run(0)
+	for i := int64(1); i < 10; i++ {
+		if diff := cmp.Diff(want, run(i)); diff != "" {
+			t.Errorf("run(%v) mismatch (-want +got):\n%v", i, diff)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package quadtree
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/internal/node"
+)
+
+type phase int
+
+const (
+	// phaseGoal searches for a path to the requested goal.
+	phaseGoal phase = iota
+
+	// phaseReachable expands all leaves reachable from the source, in
+	// order to find a substitute goal.
+	phaseReachable
+
+	// phaseNearest searches for a path to the substitute goal.
+	phaseNearest
+
+	phaseDone
+)
+
+// interval is the maximum number of leaves expanded between checks of the
+// request context, which bounds the latency of cancelling a long Step.
+const interval = 64
+
+// PathRequest is a resumable path search, which allows the cost of a single
+// search to be spread over multiple frames. The request is restarted if the
+// tree is modified between calls to Step.
+type PathRequest struct {
+	qt  *QT
+	ctx context.Context
+	s   vector.V
+	g   vector.V
+	o   pathOptions
+
+	version uint64
+	gr      graph
+	phase   phase
+	search  *search
+
+	result Result
+	err    error
+}
+
+// PathRequest returns a new resumable search from s to g. The search does not
+// run until Step is called. Cancelling the input context aborts the search.
+func (qt *QT) PathRequest(ctx context.Context, s vector.V, g vector.V, opts ...PathOption) *PathRequest {
+	return qt.request(ctx, s, g, newPathOptions(opts))
+}
+
+func (qt *QT) request(ctx context.Context, s vector.V, g vector.V, o pathOptions) *PathRequest {
+	r := &PathRequest{
+		qt:  qt,
+		ctx: ctx,
+		s:   s,
+		g:   g,
+		o:   o,
+	}
+	r.reset()
+	return r
+}
+
+func (r *PathRequest) reset() {
+	r.version = r.qt.version
+	r.gr = r.qt.graph(r.s, r.g, r.o)
+	r.result = Result{Cost: math.Inf(1)}
+	r.search = nil
+
+	switch gr := r.gr; {
+	case !finite(r.s) || !finite(r.g):
+		r.phase = phaseDone
+	case gr.src == nil || gr.blocked(gr.src):
+		r.phase = phaseDone
+	case gr.dst != nil && !gr.blocked(gr.dst):
+		// A search to a goal in a different component would expand
+		// every leaf reachable from the source before failing.
+		if leaves, ok := r.component(); ok && !leaves[gr.dst] {
+			if r.o.nearest {
+				r.substitute(leaves)
+			} else {
+				r.phase = phaseDone
+			}
+			return
+		}
+		r.phase = phaseGoal
+		r.start(gr.dst)
+	case r.o.nearest:
+		if leaves, ok := r.component(); ok {
+			r.substitute(leaves)
+			return
+		}
+		r.phase = phaseReachable
+		r.start(nil)
+	default:
+		r.phase = phaseDone
+	}
+}
+
+// component returns the leaves in the connected component of the source, if
+// the tree already tracks connectivity, i.e. ComponentID or Connected has been
+// called with the search mask, and the component labels match the search
+// graph. Component labels only consider the default edge cost.
+func (r *PathRequest) component() (map[*node.N]bool, bool) {
+	cs := r.qt.cs[r.o.mask]
+	if cs == nil || r.o.edge != nil {
+		return nil, false
+	}
+	c, ok := cs.label(r.s)
+	if !ok {
+		return nil, false
+	}
+	leaves := make(map[*node.N]bool, len(cs.members[c]))
+	for _, x := range cs.members[c] {
+		leaves[node.Find(r.qt.root, x)] = true
+	}
+	return leaves, true
+}
+
+// Step expands up to k leaves of the search, and returns true if the search
+// has finished. A non-positive k runs the search to completion. The request
+// context is checked periodically during the step, so that cancellation also
+// aborts a single long-running step.
+func (r *PathRequest) Step(k int) bool {
+	if r.phase == phaseDone {
+		return true
+	}
+	if r.version != r.qt.version {
+		r.reset()
+	}
+
+	limited := k > 0
+	for {
+		if r.phase == phaseDone {
+			return true
+		}
+		if err := r.ctx.Err(); err != nil {
+			r.err = err
+			r.phase = phaseDone
+			return true
+		}
+		if limited && k <= 0 {
+			return false
+		}
+
+		n := interval
+		if limited && k < n {
+			n = k
+		}
+		i, done := r.search.step(n)
+		r.result.Expanded += i
+		if k -= i; !done {
+			continue
+		}
+
+		switch r.phase {
+		case phaseGoal:
+			if r.search.reached() {
+				r.finish(false)
+			} else if r.o.nearest {
+				// An unsuccessful A* search will have expanded
+				// every leaf reachable from the source.
+				r.substitute(r.search.closed)
+			} else {
+				r.record()
+				r.phase = phaseDone
+			}
+		case phaseReachable:
+			r.substitute(r.search.closed)
+		case phaseNearest:
+			r.finish(true)
+		}
+	}
+}
+
+// substitute replaces the goal with the closest point to the goal within the
+// input reachable leaves, and starts a search to the substitute.
+func (r *PathRequest) substitute(leaves map[*node.N]bool) {
+	var dst *node.N
+	var p vector.V
+	min := math.Inf(1)
+	for n := range leaves {
+		q := clamp(n.AABB().Min(), n.AABB().Max(), r.g)
+		// The squared distance overflows to +Inf for distant goals, in
+		// which case all leaves tie.
+		d := squared(q, r.g)
+		// Break ties by the Morton order of the leaves.
+		if dst == nil || d < min || d == min && node.Less(n.ID(), dst.ID()) {
+			dst, p, min = n, q, d
+		}
+	}
+
+	r.gr.g, r.gr.dst = p, dst
+	r.phase = phaseNearest
+	r.start(r.gr.dst)
+}
+
+// finite checks if all coordinates of the input point are finite.
+func finite(v vector.V) bool {
+	for _, x := range v {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// start begins a new search phase from the source to the input leaf.
+func (r *PathRequest) start(dst *node.N) {
+	if r.search != nil {
+		r.record()
+	}
+	r.search = r.gr.newSearch(r.gr.src, dst, nil)
+	r.search.trace = r.o.closed
+}
+
+// record appends the leaves closed by the current search phase to the result.
+func (r *PathRequest) record() {
+	for _, n := range r.search.order {
+		r.result.Closed = append(r.result.Closed, n.ID())
+	}
+}
+
+func (r *PathRequest) finish(substituted bool) {
+	r.record()
+
+	chain := trace(r.search.parents, r.gr.dst)
+	cells := make([]string, 0, len(chain))
+	for _, n := range chain {
+		cells = append(cells, n.ID())
+	}
+
+	r.result.Path = r.gr.waypoints(chain)
+	r.result.Goal = r.gr.g
+	r.result.Substituted = substituted
+	if r.gr.edge != nil {
+		r.result.Cost = r.search.costs[r.gr.dst]
+	} else {
+		r.result.Cost = r.gr.price(chain, r.result.Path)
+	}
+	r.result.Cells = cells
+	r.phase = phaseDone
+}
+
+// Done checks if the search has finished.
+func (r *PathRequest) Done() bool { return r.phase == phaseDone }
+
+// Result returns the outcome of the search. Result returns an error if the
+// search was cancelled, or has not yet finished.
+func (r *PathRequest) Result() (Result, error) {
+	if r.err != nil {
+		return Result{}, r.err
+	}
+	if r.phase != phaseDone {
+		return Result{}, fmt.Errorf("path request has not finished")
+	}
+	return r.result, nil
+}
@@ -0,0 +1,159 @@
+package quadtree
+
+import (
+	"context"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+	"github.com/downflux/go-quadtree/id"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestPathRequest(t *testing.T) {
+	newQT := func() *QT {
+		qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 3)
+		if err := qt.Insert(1, *hyperrectangle.New(vector.V{49, 0}, vector.V{51, 80})); err != nil {
+			t.Fatalf("Insert() = %v, want = nil", err)
+		}
+		return qt
+	}
+	s, g := vector.V{10, 10}, vector.V{90, 10}
+
+	t.Run("Budget", func(t *testing.T) {
+		qt := newQT()
+		r := qt.PathRequest(context.Background(), s, g)
+
+		if _, err := r.Result(); err == nil {
+			t.Errorf("Result() = _, nil, want a non-nil error")
+		}
+
+		steps := 0
+		for !r.Step(1) {
+			steps++
+		}
+		if steps == 0 {
+			t.Errorf("Step() finished in a single expansion")
+		}
+
+		got, err := r.Result()
+		if err != nil {
+			t.Fatalf("Result() = _, %v, want = _, nil", err)
+		}
+		if diff := cmp.Diff(qt.Path(s, g), got); diff != "" {
+			t.Errorf("Result() mismatch (-want +got):\n%v", diff)
+		}
+	})
+
+	t.Run("Nearest", func(t *testing.T) {
+		qt := newQT()
+		g := vector.V{50, 10}
+		r := qt.PathRequest(context.Background(), s, g, WithNearest())
+		for !r.Step(2) {
+		}
+		got, err := r.Result()
+		if err != nil {
+			t.Fatalf("Result() = _, %v, want = _, nil", err)
+		}
+		if diff := cmp.Diff(qt.Path(s, g, WithNearest()), got); diff != "" {
+			t.Errorf("Result() mismatch (-want +got):\n%v", diff)
+		}
+		if !got.Substituted {
+			t.Errorf("Substituted = %v, want = %v", got.Substituted, true)
+		}
+	})
+
+	t.Run("Restart", func(t *testing.T) {
+		qt := newQT()
+		r := qt.PathRequest(context.Background(), s, g)
+		r.Step(1)
+		if err := qt.Remove(1); err != nil {
+			t.Fatalf("Remove() = %v, want = nil", err)
+		}
+		r.Step(0)
+
+		got, err := r.Result()
+		if err != nil {
+			t.Fatalf("Result() = _, %v, want = _, nil", err)
+		}
+		if diff := cmp.Diff(qt.Path(s, g), got); diff != "" {
+			t.Errorf("Result() mismatch (-want +got):\n%v", diff)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		qt := newQT()
+		ctx, cancel := context.WithCancel(context.Background())
+		r := qt.PathRequest(ctx, s, g)
+		r.Step(1)
+		cancel()
+		if !r.Step(1) {
+			t.Errorf("Step() = false, want = true after cancellation")
+		}
+		if _, err := r.Result(); err != context.Canceled {
+			t.Errorf("Result() = _, %v, want = _, %v", err, context.Canceled)
+		}
+	})
+	t.Run("Components", func(t *testing.T) {
+		for _, g := range []vector.V{{50, 10}, {150, 10}} {
+			qt := newQT()
+			want := qt.Clone().Path(s, g, WithNearest())
+
+			// Track connectivity, which allows the request to
+			// skip expanding all reachable leaves.
+			qt.Connected(s, s, LayerAll)
+			got := qt.Path(s, g, WithNearest())
+
+			if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Result{}, "Expanded")); diff != "" {
+				t.Errorf("Path() mismatch (-want +got):\n%v", diff)
+			}
+			if got.Expanded >= want.Expanded {
+				t.Errorf("Expanded = %v, want < %v", got.Expanded, want.Expanded)
+			}
+		}
+	})
+
+	t.Run("CancelDuringStep", func(t *testing.T) {
+		qt := New(*hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100}), 0, 6)
+		for i := 0; i < 10; i++ {
+			for j := 0; j < 10; j++ {
+				x, y := float64(10*i+4), float64(10*j+4)
+				if err := qt.Insert(id.ID(10*i+j), *hyperrectangle.New(vector.V{x, y}, vector.V{x + 1, y + 1})); err != nil {
+					t.Fatalf("Insert() = %v, want = nil", err)
+				}
+			}
+		}
+		s, g := vector.V{1, 1}, vector.V{99, 99}
+
+		want := qt.Path(s, g)
+		if want.Expanded <= 2*interval {
+			t.Fatalf("Expanded = %v, want > %v", want.Expanded, 2*interval)
+		}
+
+		ctx := &countdown{Context: context.Background(), n: 2}
+		r := qt.PathRequest(ctx, s, g)
+		if !r.Step(0) {
+			t.Errorf("Step() = false, want = true after cancellation")
+		}
+		if _, err := r.Result(); err != context.Canceled {
+			t.Errorf("Result() = _, %v, want = _, %v", err, context.Canceled)
+		}
+		if got := r.result.Expanded; got >= want.Expanded {
+			t.Errorf("Expanded = %v, want < %v", got, want.Expanded)
+		}
+	})
+}
+
+// countdown is a context which is cancelled once Err has been called n times.
+type countdown struct {
+	context.Context
+	n int
+}
+
+func (c *countdown) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package quadtree
+
+import (
+	"fmt"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-quadtree/id"
+)
+
+// Validate checks the structural invariants of the tree, and returns an error
+// describing the first violation found. Validate is meant for tests and
+// fuzzers, and runs in time linear in the size of the tree.
+//
+// Empty or fully covered sibling leaves are only reported if the tree merges
+// eagerly. See SetMergeDelay.
+func (qt *QT) Validate() error {
+	if len(qt.aabb) != len(qt.objects) {
+		return fmt.Errorf("tree stores %v AABBs for %v objects", len(qt.aabb), len(qt.objects))
+	}
+	for x := range qt.aabb {
+		if _, ok := qt.objects[x]; !ok {
+			return fmt.Errorf("object %v has an AABB but no properties", x)
+		}
+	}
+
+	if qt.loose != nil {
+		if err := qt.loose.validate(qt.aabb); err != nil {
+			return err
+		}
+		// Loose trees do not store any objects in the cell tree.
+		return qt.root.Validate(map[id.ID]hyperrectangle.R{}, true)
+	}
+	return qt.root.Validate(qt.aabb, qt.delay == 0)
+}
+
+func (l *loose) validate(data map[id.ID]hyperrectangle.R) error {
+	if len(l.nodes) != len(data) {
+		return fmt.Errorf("loose tree tracks %v objects, want %v", len(l.nodes), len(data))
+	}
+	for x, aabb := range data {
+		n, ok := l.nodes[x]
+		if !ok {
+			return fmt.Errorf("loose tree does not store object %v", x)
+		}
+		if !n.objects[x] {
+			return fmt.Errorf("loose node %q: does not store tracked object %v", n.id, x)
+		}
+		if m, missing := l.target(aabb); m != n || len(missing) != 0 {
+			return fmt.Errorf("loose node %q: stores object %v which belongs in a different node", n.id, x)
+		}
+	}
+
+	open := []*lnode{l.root}
+	var n *lnode
+	for len(open) > 0 {
+		n, open = open[0], open[1:]
+		count := len(n.objects)
+		for x := range n.objects {
+			if l.nodes[x] != n {
+				return fmt.Errorf("loose node %q: stores untracked object %v", n.id, x)
+			}
+		}
+		for i, c := range n.children {
+			if c == nil {
+				continue
+			}
+			if c.parent != n || c.depth != n.depth+1 || c.id != fmt.Sprintf("%v%d", n.id, i) {
+				return fmt.Errorf("loose node %q: inconsistent with the parent %q", c.id, n.id)
+			}
+			if c.count == 0 {
+				return fmt.Errorf("loose node %q: empty subtree was not discarded", c.id)
+			}
+			count += c.count
+			open = append(open, c)
+		}
+		if count != n.count {
+			return fmt.Errorf("loose node %q: count %v does not match the subtree size %v", n.id, n.count, count)
+		}
+	}
+	return nil
+}
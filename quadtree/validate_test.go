@@ -0,0 +1,72 @@
+package quadtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/downflux/go-geometry/nd/hyperrectangle"
+	"github.com/downflux/go-geometry/nd/vector"
+)
+
+func TestValidate(t *testing.T) {
+	bounds := *hyperrectangle.New(vector.V{0, 0}, vector.V{100, 100})
+
+	type config struct {
+		name string
+		qt   func() *QT
+	}
+
+	configs := []config{
+		{name: "Default", qt: func() *QT { return New(bounds, 1, 5) }},
+		{name: "Capacity", qt: func() *QT { return NewWithPolicy(bounds, Capacity(2), 5) }},
+		{name: "Loose", qt: func() *QT { return NewLoose(bounds, 2, 5) }},
+		{
+			name: "Delayed",
+			qt: func() *QT {
+				qt := New(bounds, 1, 5)
+				qt.SetMergeDelay(3)
+				return qt
+			},
+		},
+		{
+			name: "Expand",
+			qt: func() *QT {
+				qt := New(bounds, 1, 5)
+				qt.SetExpand(true)
+				return qt
+			},
+		},
+		{
+			name: "Clip",
+			qt: func() *QT {
+				qt := New(bounds, 1, 5)
+				qt.SetBoundsPolicy(BoundsClip)
+				return qt
+			},
+		},
+	}
+
+	for _, c := range configs {
+		t.Run(c.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(0))
+			qt := c.qt()
+			if err := qt.Validate(); err != nil {
+				t.Fatalf("Validate() = %v, want = nil", err)
+			}
+			for i := 0; i < 200; i++ {
+				before := qt.Checksum()
+
+				// Objects may extend past the bounds of the tree.
+				err := mutate(r, qt, func(r *rand.Rand) hyperrectangle.R { return rr(r, -10, 110) })
+				// Rejected inputs must leave the tree unchanged.
+				if err != nil && qt.Checksum() != before {
+					t.Fatalf("Checksum() = %v, want = %v", qt.Checksum(), before)
+				}
+
+				if err := qt.Validate(); err != nil {
+					t.Fatalf("Validate() = %v, want = nil", err)
+				}
+			}
+		})
+	}
+}